@@ -9,5 +9,5 @@ func main() {
 	cli := modules.NewCLITool("1.0.2")
 
 	args := os.Args[1:] // Skip program name
-	cli.Run(args)
+	os.Exit(cli.Run(args))
 }
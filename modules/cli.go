@@ -1,24 +1,31 @@
 package modules
 
 import (
-	"fmt"
+	"os"
+	"os/signal"
 	"strings"
+	"sync/atomic"
+	"syscall"
 )
 
 // XyPriss ASCII art logo
-const XyPrissLogo = ColorCyan + `
+const XyPrissLogo = `
 ██╗  ██╗██╗   ██╗██████╗ ██████╗ ██╗███████╗███████╗
 ╚██╗██╔╝╚██╗ ██╔╝██╔══██╗██╔══██╗██║██╔════╝██╔════╝
  ╚███╔╝  ╚████╔╝ ██████╔╝██████╔╝██║███████╗███████╗
  ██╔██╗   ╚██╔╝  ██╔═══╝ ██╔══██╗██║╚════██║╚════██║
 ██╔╝ ██╗   ██║   ██║     ██║  ██║██║███████║███████║
 ╚═╝  ╚═╝   ╚═╝   ╚═╝     ╚═╝  ╚═╝╚═╝╚══════╝╚══════╝
-` + ColorReset + ColorBlue + `
+`
+
+// XyPrissTagline is printed under the logo
+const XyPrissTagline = `
             ⚡ High-Performance Node.js Framework ⚡
-` + ColorReset
+`
 
 // ANSI color codes for beautiful output
-const (
+// They are variables so the output layer can blank them (NO_COLOR, --no-color, non-TTY)
+var (
 	ColorReset     = "\033[0m"
 	ColorRed       = "\033[31m"
 	ColorGreen     = "\033[32m"
@@ -35,12 +42,17 @@ const (
 // This tool provides commands for initializing new projects and managing
 // XyPriss applications
 type CLITool struct {
-	version string // CLI version
+	version     string      // CLI version
+	events      *EventSink  // Machine-readable output (--output json|ndjson)
+	settings    *Settings   // User and project configuration (never nil while a command runs)
+	settingsErr error       // Why the configuration files could not be loaded, if they could not
+	ownSignals  atomic.Bool // The running command handles SIGINT/SIGTERM itself
+	gotSignal   atomic.Bool // A SIGINT/SIGTERM was received
 }
  
 // NewCLITool creates a new CLI tool instance
 func NewCLITool(version string) *CLITool {
-	return &CLITool{version: version}
+	return &CLITool{version: version, events: NewEventSink(OutputText, os.Stdout)}
 }
 
 // ShowHelp displays the CLI help information with beautiful branding
@@ -50,85 +62,167 @@ func NewCLITool(version string) *CLITool {
 // - Usage syntax with colored output
 // - Example command invocations
 // - Version information
+// Everything but the branding is generated from the command registry
 func (c *CLITool) ShowHelp() {
-	fmt.Println(XyPrissLogo)
-	fmt.Printf("%sCLI Tool v%s%s\n\n", ColorYellow, c.version, ColorReset)
-	fmt.Printf("%sUSAGE:%s\n", ColorBold, ColorReset)
-	fmt.Printf("  %sxypcli <command> [options]%s\n", ColorCyan, ColorReset)
-	fmt.Println()
-	fmt.Printf("%sCOMMANDS:%s\n", ColorBold, ColorReset)
-	fmt.Printf("  %sinit%s     Initialize a new XyPriss project with all necessary configuration\n", ColorGreen, ColorReset)
-	fmt.Printf("  %sstart%s    Start the XyPriss development server in the current directory\n", ColorGreen, ColorReset)
-	fmt.Printf("  %sinstall%s  Install one or more packages using the XyPriss installation system\n", ColorGreen, ColorReset)
-	fmt.Printf("  %sversion%s  Show CLI version information\n", ColorGreen, ColorReset)
-	fmt.Printf("  %shelp%s     Show this help message\n", ColorGreen, ColorReset)
-	fmt.Println()
-	fmt.Printf("%sINIT OPTIONS:%s\n", ColorBold, ColorReset)
-	fmt.Printf("  %s--name <name>%s         Project name (default: interactive prompt)\n", ColorCyan, ColorReset)
-	fmt.Printf("  %s--desc <description>%s  Project description\n", ColorCyan, ColorReset)
-	fmt.Printf("  %s--lang <js|ts>%s        Programming language (default: ts)\n", ColorCyan, ColorReset)
-	fmt.Printf("  %s--port <port>%s         Server port (default: 3000)\n", ColorCyan, ColorReset)
-	fmt.Printf("  %s--version <version>%s   Application version (default: 1.0.0)\n", ColorCyan, ColorReset)
-	fmt.Printf("  %s--alias <alias>%s       Application alias (default: XyP)\n", ColorCyan, ColorReset)
-	fmt.Printf("  %s--author <author>%s     Author name (default: Nehonix-Team)\n", ColorCyan, ColorReset)
-	fmt.Printf("  %s--mode <b|n>%s          Installation mode: 'b' for bun, 'n' for npm (default: auto)\n", ColorCyan, ColorReset)
-	fmt.Printf("  %s--strict%s              Exit immediately if any package installation fails\n", ColorCyan, ColorReset)
-	fmt.Println()
-	fmt.Printf("%sINSTALL OPTIONS:%s\n", ColorBold, ColorReset)
-	fmt.Printf("  %s--mode <b|n>%s          Installation mode: 'b' for bun, 'n' for npm (default: auto)\n", ColorCyan, ColorReset)
-	fmt.Println()
-	fmt.Printf("%sEXAMPLES:%s\n", ColorBold, ColorReset)
-	fmt.Printf("  %sxypcli init%s                                    # Interactive mode\n", ColorMagenta, ColorReset)
-	fmt.Printf("  %sxypcli init --name my-app --port 8080%s         # Quick init with options\n", ColorMagenta, ColorReset)
-	fmt.Printf("  %sxypcli init --name my-app --mode n%s            # Force npm installation\n", ColorMagenta, ColorReset)
-	fmt.Printf("  %sxypcli start%s                                   # Start development server\n", ColorMagenta, ColorReset)
-	fmt.Printf("  %sxypcli install xypriss cors%s                    # Install multiple packages\n", ColorMagenta, ColorReset)
-	fmt.Printf("  %sxypcli install xypriss --mode b%s                # Install with bun\n", ColorMagenta, ColorReset)
-	fmt.Printf("  %sxypcli --version%s                               # Show CLI version\n", ColorMagenta, ColorReset)
-	fmt.Printf("  %sxypcli help%s                                    # Show this help\n", ColorMagenta, ColorReset)
-	fmt.Println()
-	fmt.Printf("%sFor more information, visit: %shttps://github.com/Nehonix-Team/XyPriss%s\n", ColorDim, ColorBlue, ColorReset)
+	c.showCommandHelp(c.commandTree())
+}
+
+// showCommandHelp displays the generated help of a command
+// The top-level help also shows the logo and the CLI version
+func (c *CLITool) showCommandHelp(cmd *Command) {
+	if cmd.parent == nil {
+		printLogo()
+		printf("%sCLI Tool v%s%s\n\n", ColorYellow, c.version, ColorReset)
+	}
+	printCommandHelp(cmd, globalFlags)
+	if cmd.parent == nil {
+		printLine()
+		printf("%sFor more information, visit: %shttps://github.com/Nehonix-Team/XyPriss%s\n", ColorDim, ColorBlue, ColorReset)
+	}
 }
 
 // Run executes the CLI tool with the given command line arguments
-func (c *CLITool) Run(args []string) {
-	if len(args) < 1 {
-		c.ShowHelp()
-		return
-	}
-
-	command := args[0]
-
-	switch command {
-	case "init":
-		// Parse init flags
-		initFlags := parseInitFlags(args[1:])
-		c.InitProject(initFlags)
-	case "start":
-		c.StartServer()
-	case "install":
-		if len(args) < 2 {
-			fmt.Printf("%s❌ Package name required%s\n", ColorRed, ColorReset)
-			fmt.Printf("%sUsage:%s xypcli install <package-name> [package-name...] [--mode <b|n>]\n", ColorBold, ColorReset)
-			return
+// Returns the process exit code (see the Exit* constants)
+func (c *CLITool) Run(args []string) int {
+	ctx, err := parseCommandLine(c.commandTree(), globalFlags, args)
+	output := ctx.String("output")
+	if ctx.Bool("json") {
+		output = OutputJSON
+	}
+	if err != nil {
+		output = requestedOutput(args)
+	}
+	if output == "" {
+		output = OutputText
+	}
+	c.setupOutput(output)
+	c.settings, c.settingsErr = LoadSettings(ctx.String("profile"))
+	configureConsole(OutputOptions{
+		Color:   c.settings.String("color"),
+		NoColor: ctx.Bool("no-color"),
+		NoEmoji: ctx.Bool("no-emoji"),
+		Quiet:   ctx.Bool("quiet"),
+	})
+
+	cmd := ctx.Command
+	if path := cmd.Path(); path != "" {
+		c.events.Begin(path)
+	} else if len(args) > 0 && err != nil {
+		c.events.Begin(args[0])
+	}
+	if err != nil {
+		return c.finish(err)
+	}
+
+	if ctx.Bool("help") {
+		c.showCommandHelp(cmd)
+		return ExitOK
+	}
+	if cmd.parent == nil && ctx.Bool("version") {
+		resultf("XyPCLI v%s\n", c.version)
+		return ExitOK
+	}
+	if cmd.Run == nil {
+		// Command groups (and xypcli itself) show their help when called without a subcommand
+		c.showCommandHelp(cmd)
+		return ExitOK
+	}
+
+	stop := c.watchSignals()
+	defer stop()
+
+	if err := validateArgs(ctx); err != nil {
+		return c.finish(err)
+	}
+	return c.finish(cmd.Run(c, ctx))
+}
+
+// finish is the single top-level error handler: it prints the error, completes
+// machine-readable output and returns the exit code for the error
+func (c *CLITool) finish(err error) int {
+	if err == nil {
+		c.events.Close(ExitOK, nil)
+		return ExitOK
+	}
+
+	cliErr := asCLIError(err)
+	if cliErr.Kind == KindInterrupted {
+		printf("\n%s⚠ Interrupted%s\n", ColorYellow, ColorReset)
+	} else {
+		printf("%s❌ %s%s\n", ColorRed, capitalize(cliErr.Error()), ColorReset)
+		if cliErr.Hint != "" {
+			resultf("   %s→ %s%s\n", ColorYellow, cliErr.Hint, ColorReset)
+		}
+	}
+	code := cliErr.ExitCode()
+	c.events.Close(code, cliErr)
+	return code
+}
+
+// requestedOutput finds the output mode in arguments that failed to parse,
+// so usage errors are still reported in the format the caller asked for
+func requestedOutput(args []string) string {
+	output := ""
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return output
+		case arg == "--json":
+			output = OutputJSON
+		case arg == "--output" && i+1 < len(args):
+			output = args[i+1]
+		case strings.HasPrefix(arg, "--output="):
+			output = strings.TrimPrefix(arg, "--output=")
 		}
-		// Parse install flags and packages
-		packages, mode := parseInstallArgs(args[1:])
-		if len(packages) == 0 {
-			fmt.Printf("%s❌ At least one package name required%s\n", ColorRed, ColorReset)
-			return
+	}
+	if output != OutputJSON && output != OutputNDJSON {
+		return OutputText
+	}
+	return output
+}
+
+// watchSignals turns SIGINT/SIGTERM into the "interrupted" exit code
+// Commands that supervise a child process call handleSignals and stay in control
+func (c *CLITool) watchSignals() func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-signals:
+			c.gotSignal.Store(true)
+			if !c.ownSignals.Load() {
+				os.Exit(c.finish(interruptedError()))
+			}
+		case <-done:
 		}
-		c.InstallPackages(packages, mode)
-	case "version", "-v", "--version":
-		fmt.Printf("XyPCLI v%s\n", c.version)
-	case "help", "-h", "--help":
-		c.ShowHelp()
-	default:
-		fmt.Printf("Unknown command: %s\n\n", command)
-		c.ShowHelp()
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
 	}
 }
 
+// handleSignals tells the signal watcher that the running command handles interruption itself
+func (c *CLITool) handleSignals() {
+	c.ownSignals.Store(true)
+}
+
+// interrupted reports whether SIGINT/SIGTERM was received
+func (c *CLITool) interrupted() bool {
+	return c.gotSignal.Load()
+}
+
+// capitalize upper-cases the first letter of a message
+func capitalize(message string) string {
+	if message == "" {
+		return message
+	}
+	return strings.ToUpper(message[:1]) + message[1:]
+}
+
 // InitFlags holds command-line flags for the init command
 type InitFlags struct {
 	Name        string
@@ -140,75 +234,7 @@ type InitFlags struct {
 	Author      string
 	Mode        string
 	Strict      bool   // Exit on first installation error
+	License     string // SPDX license for package.json
+	Env         string // Environment written to __sys__.__env__
+	Template    string // Template zip: URL or local path ("" for the Nehonix SDK)
 }
-
-// parseInitFlags parses command-line flags for the init command
-func parseInitFlags(args []string) InitFlags {
-	flags := InitFlags{}
-	
-	for i := 0; i < len(args); i++ {
-		if !strings.HasPrefix(args[i], "--") {
-			continue
-		}
-		
-		flag := args[i]
-		var value string
-		
-		// Get the value (next argument or after =)
-		if strings.Contains(flag, "=") {
-			parts := strings.SplitN(flag, "=", 2)
-			flag = parts[0]
-			value = parts[1]
-		} else if i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
-			value = args[i+1]
-			i++ // Skip next arg since we used it as value
-		}
-		
-		switch flag {
-		case "--name":
-			flags.Name = value
-		case "--desc", "--description":
-			flags.Description = value
-		case "--lang", "--language":
-			flags.Language = value
-		case "--port":
-			flags.Port = value
-		case "--version":
-			flags.Version = value
-		case "--alias":
-			flags.Alias = value
-		case "--author":
-			flags.Author = value
-		case "--mode":
-			flags.Mode = value
-		case "--strict":
-			flags.Strict = true
-		}
-	}
-	
-	return flags
-}
-
-// parseInstallArgs parses arguments for the install command
-// Returns the list of packages and the installation mode
-func parseInstallArgs(args []string) ([]string, string) {
-	packages := []string{}
-	mode := ""
-	
-	for i := 0; i < len(args); i++ {
-		if strings.HasPrefix(args[i], "--") {
-			if args[i] == "--mode" || strings.HasPrefix(args[i], "--mode=") {
-				if strings.Contains(args[i], "=") {
-					mode = strings.SplitN(args[i], "=", 2)[1]
-				} else if i+1 < len(args) {
-					mode = args[i+1]
-					i++
-				}
-			}
-		} else {
-			packages = append(packages, args[i])
-		}
-	}
-	
-	return packages, mode
-}
\ No newline at end of file
@@ -0,0 +1,516 @@
+package modules
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FlagType is the value type of a command-line flag
+type FlagType int
+
+const (
+	FlagString FlagType = iota // --name value
+	FlagBool                   // --strict (or --strict=false)
+	FlagInt                    // --port 3000
+)
+
+// FlagDef describes a single command-line flag
+// The same definitions drive parsing, help, shell completion and generated docs
+type FlagDef struct {
+	Name    string   // Long name without dashes (e.g. "name")
+	Short   string   // Optional one-letter short name (e.g. "q")
+	Aliases []string // Other accepted long names (e.g. "desc" for "description")
+	Type    FlagType // Value type
+	Value   string   // Value placeholder shown in help (e.g. "<name>")
+	Usage   string   // One line description
+	Default string   // Default shown in help (informational)
+	Values  []string // Allowed values; empty means any value
+
+	// Complete returns dynamic values for shell completion (e.g. names read from disk)
+	Complete func() []string
+}
+
+// Example is a documented invocation of a command
+type Example struct {
+	Command string
+	Comment string
+}
+
+// Command describes a CLI command, its flags and its subcommands
+type Command struct {
+	Name        string
+	Aliases     []string
+	Summary     string // One line description shown in command lists
+	Description string // Longer description shown in command help
+	Args        string // Positional arguments synopsis (e.g. "<package> [package...]")
+	MinArgs     int    // Minimum number of positional arguments
+	MaxArgs     int    // Maximum number of positional arguments (-1 = unlimited)
+	Flags       []FlagDef
+	Examples    []Example
+	Subcommands []*Command
+	Hidden      bool // Not listed in help, completion or docs
+	Run         func(c *CLITool, ctx *CommandContext) error
+
+	// CompleteArgs returns the values offered by shell completion for positional arguments
+	CompleteArgs func() []string
+
+	parent *Command
+}
+
+// CommandContext holds the parsed arguments for a command invocation
+type CommandContext struct {
+	Command *Command
+	Args    []string
+	values  map[string]string
+	set     map[string]bool
+}
+
+// String returns the value of a string flag (or "" if not set)
+func (ctx *CommandContext) String(name string) string {
+	return ctx.values[name]
+}
+
+// Bool returns the value of a boolean flag
+func (ctx *CommandContext) Bool(name string) bool {
+	return ctx.values[name] == "true"
+}
+
+// Int returns the value of an integer flag (or 0 if not set)
+func (ctx *CommandContext) Int(name string) int {
+	value, _ := strconv.Atoi(ctx.values[name])
+	return value
+}
+
+// IsSet reports whether a flag was given on the command line
+func (ctx *CommandContext) IsSet(name string) bool {
+	return ctx.set[name]
+}
+
+// Path returns the full command path without the program name (e.g. "config get")
+func (cmd *Command) Path() string {
+	if cmd.parent == nil {
+		return ""
+	}
+	if cmd.parent.parent == nil {
+		return cmd.Name
+	}
+	return cmd.parent.Path() + " " + cmd.Name
+}
+
+// FullName returns the command as typed by users (e.g. "xypcli config get")
+func (cmd *Command) FullName() string {
+	if path := cmd.Path(); path != "" {
+		return "xypcli " + path
+	}
+	return "xypcli"
+}
+
+// link sets the parent pointers of the whole command tree
+func (cmd *Command) link() *Command {
+	for _, sub := range cmd.Subcommands {
+		sub.parent = cmd
+		sub.link()
+	}
+	return cmd
+}
+
+// VisibleSubcommands returns the subcommands listed in help, completion and docs
+func (cmd *Command) VisibleSubcommands() []*Command {
+	visible := []*Command{}
+	for _, sub := range cmd.Subcommands {
+		if !sub.Hidden {
+			visible = append(visible, sub)
+		}
+	}
+	return visible
+}
+
+// findSubcommand returns the subcommand with the given name or alias
+func (cmd *Command) findSubcommand(name string) *Command {
+	for _, sub := range cmd.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+		for _, alias := range sub.Aliases {
+			if alias == name {
+				return sub
+			}
+		}
+	}
+	return nil
+}
+
+// lookupFlag finds a flag definition by long name, alias or short name
+func lookupFlag(flags []FlagDef, name string, short bool) (FlagDef, bool) {
+	for _, flag := range flags {
+		if short {
+			if flag.Short != "" && flag.Short == name {
+				return flag, true
+			}
+			continue
+		}
+		if flag.Name == name {
+			return flag, true
+		}
+		for _, alias := range flag.Aliases {
+			if alias == name {
+				return flag, true
+			}
+		}
+	}
+	return FlagDef{}, false
+}
+
+// parseCommandLine resolves the command to run and parses its flags and arguments
+// Flags may appear anywhere; "--" ends flag parsing. globalFlags are accepted by every command
+// On error the returned context holds everything parsed so far (e.g. the output mode)
+func parseCommandLine(root *Command, globalFlags []FlagDef, args []string) (*CommandContext, error) {
+	ctx := &CommandContext{Command: root, values: map[string]string{}, set: map[string]bool{}}
+	flagsOnly := false
+
+	setFlag := func(flag FlagDef, value string, display string) error {
+		switch flag.Type {
+		case FlagBool:
+			if value == "" {
+				value = "true"
+			}
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return usageError("invalid value '%s' for %s (expected true or false)", value, display)
+			}
+			value = strconv.FormatBool(parsed)
+		case FlagInt:
+			if _, err := strconv.Atoi(value); err != nil {
+				return usageError("invalid value '%s' for %s (expected a number)", value, display)
+			}
+		}
+		if len(flag.Values) > 0 && !containsString(flag.Values, value) {
+			return usageError("invalid value '%s' for %s (expected %s)", value, display, strings.Join(flag.Values, ", "))
+		}
+		ctx.values[flag.Name] = value
+		ctx.set[flag.Name] = true
+		return nil
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// Positional argument or subcommand
+		if flagsOnly || arg == "-" || !strings.HasPrefix(arg, "-") {
+			if !flagsOnly && len(ctx.Args) == 0 && len(ctx.Command.Subcommands) > 0 {
+				sub := ctx.Command.findSubcommand(arg)
+				if sub == nil {
+					if ctx.Command.Run != nil {
+						ctx.Args = append(ctx.Args, arg)
+						continue
+					}
+					return ctx, unknownCommandError(ctx.Command, arg)
+				}
+				ctx.Command = sub
+				continue
+			}
+			ctx.Args = append(ctx.Args, arg)
+			continue
+		}
+
+		if arg == "--" {
+			flagsOnly = true
+			continue
+		}
+
+		available := append(append([]FlagDef{}, ctx.Command.Flags...), globalFlags...)
+
+		// Long flag: --name, --name=value
+		if strings.HasPrefix(arg, "--") {
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			flag, ok := lookupFlag(available, name, false)
+			if !ok {
+				return ctx, unknownFlagError(ctx.Command, available, "--"+name)
+			}
+			if flag.Type != FlagBool && !hasValue {
+				if i+1 >= len(args) {
+					return ctx, usageError("--%s requires a value", name).WithHint("Usage: %s", flagSynopsis(flag))
+				}
+				i++
+				value = args[i]
+			}
+			if err := setFlag(flag, value, "--"+name); err != nil {
+				return ctx, err
+			}
+			continue
+		}
+
+		// Short flags: -q, -p 3000, -p3000, grouped booleans -qn
+		shorts := arg[1:]
+		for j := 0; j < len(shorts); j++ {
+			name := string(shorts[j])
+			flag, ok := lookupFlag(available, name, true)
+			if !ok {
+				return ctx, unknownFlagError(ctx.Command, available, "-"+name)
+			}
+			if flag.Type == FlagBool {
+				if err := setFlag(flag, "", "-"+name); err != nil {
+					return ctx, err
+				}
+				continue
+			}
+			value := strings.TrimPrefix(shorts[j+1:], "=")
+			if value == "" {
+				if i+1 >= len(args) {
+					return ctx, usageError("-%s requires a value", name).WithHint("Usage: %s", flagSynopsis(flag))
+				}
+				i++
+				value = args[i]
+			}
+			if err := setFlag(flag, value, "-"+name); err != nil {
+				return ctx, err
+			}
+			break
+		}
+	}
+
+	return ctx, nil
+}
+
+// validateArgs checks the number of positional arguments of a parsed command
+func validateArgs(ctx *CommandContext) error {
+	cmd := ctx.Command
+	if len(ctx.Args) < cmd.MinArgs {
+		return usageError("missing argument %s for %s", cmd.Args, cmd.FullName()).
+			WithHint("Usage: %s", commandSynopsis(cmd))
+	}
+	if cmd.MaxArgs >= 0 && len(ctx.Args) > cmd.MaxArgs {
+		if len(cmd.Subcommands) > 0 && len(ctx.Args) > 0 {
+			return unknownCommandError(cmd, ctx.Args[0])
+		}
+		return usageError("unexpected argument '%s' for %s", ctx.Args[cmd.MaxArgs], cmd.FullName()).
+			WithHint("Usage: %s", commandSynopsis(cmd))
+	}
+	return nil
+}
+
+// unknownCommandError builds a usage error with a "did you mean" suggestion
+func unknownCommandError(parent *Command, name string) *CLIError {
+	candidates := []string{}
+	for _, sub := range parent.VisibleSubcommands() {
+		candidates = append(candidates, sub.Name)
+		candidates = append(candidates, sub.Aliases...)
+	}
+	err := usageError("unknown command '%s' for %s", name, parent.FullName())
+	if suggestion := suggest(name, candidates); suggestion != "" {
+		return err.WithHint("Did you mean '%s %s'?", parent.FullName(), suggestion)
+	}
+	return err.WithHint("Run '%s --help' to list the available commands", parent.FullName())
+}
+
+// unknownFlagError builds a usage error with a "did you mean" suggestion
+func unknownFlagError(cmd *Command, flags []FlagDef, name string) *CLIError {
+	candidates := []string{}
+	for _, flag := range flags {
+		candidates = append(candidates, "--"+flag.Name)
+		for _, alias := range flag.Aliases {
+			candidates = append(candidates, "--"+alias)
+		}
+	}
+	err := usageError("unknown flag '%s' for %s", name, cmd.FullName())
+	if suggestion := suggest(name, candidates); suggestion != "" {
+		return err.WithHint("Did you mean '%s'?", suggestion)
+	}
+	return err.WithHint("Run '%s --help' to list the available options", cmd.FullName())
+}
+
+// suggest returns the closest candidate to name, or "" if none is close enough
+func suggest(name string, candidates []string) string {
+	best, bestDistance := "", -1
+	for _, candidate := range candidates {
+		distance := levenshtein(name, candidate)
+		if strings.HasPrefix(candidate, name) && len(name) >= 3 {
+			distance = 1
+		}
+		if bestDistance == -1 || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	limit := len(name) / 3
+	if limit < 2 {
+		limit = 2
+	}
+	if bestDistance >= 0 && bestDistance <= limit {
+		return best
+	}
+	return ""
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// minInt returns the smaller of two integers
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// flagSynopsis renders a flag as shown in help (e.g. "-p, --port <port>")
+func flagSynopsis(flag FlagDef) string {
+	synopsis := "--" + flag.Name
+	if flag.Short != "" {
+		synopsis = "-" + flag.Short + ", " + synopsis
+	}
+	if flag.Type != FlagBool {
+		placeholder := flag.Value
+		if placeholder == "" {
+			placeholder = "<" + flag.Name + ">"
+		}
+		synopsis += " " + placeholder
+	}
+	return synopsis
+}
+
+// flagDescription renders the help description of a flag, including defaults and aliases
+func flagDescription(flag FlagDef) string {
+	description := flag.Usage
+	if flag.Default != "" {
+		description += " (default: " + flag.Default + ")"
+	}
+	aliases := []string{}
+	for _, alias := range flag.Aliases {
+		aliases = append(aliases, "--"+alias)
+	}
+	if len(aliases) > 0 {
+		description += " [alias: " + strings.Join(aliases, ", ") + "]"
+	}
+	return description
+}
+
+// commandSynopsis renders the usage line of a command
+func commandSynopsis(cmd *Command) string {
+	synopsis := cmd.FullName()
+	if len(cmd.VisibleSubcommands()) > 0 && cmd.Run == nil {
+		synopsis += " <command>"
+	}
+	if len(cmd.Flags) > 0 {
+		synopsis += " [options]"
+	}
+	if cmd.Args != "" {
+		synopsis += " " + cmd.Args
+	}
+	return synopsis
+}
+
+// helpRow is one "name  description" line of a help section
+type helpRow struct {
+	name        string
+	description string
+}
+
+// printHelpSection prints an aligned section of the help output
+func printHelpSection(title, color string, rows []helpRow) {
+	if len(rows) == 0 {
+		return
+	}
+	width := 0
+	for _, row := range rows {
+		if len(row.name) > width {
+			width = len(row.name)
+		}
+	}
+	printf("%s%s:%s\n", ColorBold, title, ColorReset)
+	for _, row := range rows {
+		printf("  %s%s%s%s  %s\n", color, row.name, ColorReset, strings.Repeat(" ", width-len(row.name)), row.description)
+	}
+	printLine()
+}
+
+// flagRows converts flag definitions to help rows
+func flagRows(flags []FlagDef) []helpRow {
+	rows := []helpRow{}
+	for _, flag := range flags {
+		rows = append(rows, helpRow{flagSynopsis(flag), flagDescription(flag)})
+	}
+	return rows
+}
+
+// exampleRows converts examples to help rows
+func exampleRows(examples []Example) []helpRow {
+	rows := []helpRow{}
+	for _, example := range examples {
+		rows = append(rows, helpRow{example.Command, "# " + example.Comment})
+	}
+	return rows
+}
+
+// subcommandRows lists the visible subcommands of a command
+func subcommandRows(cmd *Command) []helpRow {
+	rows := []helpRow{}
+	for _, sub := range cmd.VisibleSubcommands() {
+		rows = append(rows, helpRow{sub.Name, sub.Summary})
+	}
+	return rows
+}
+
+// printCommandHelp prints the generated help of a single command
+func printCommandHelp(cmd *Command, globalFlags []FlagDef) {
+	printf("%sUSAGE:%s\n", ColorBold, ColorReset)
+	printf("  %s%s%s\n\n", ColorCyan, commandSynopsis(cmd), ColorReset)
+
+	description := cmd.Description
+	if description == "" {
+		description = cmd.Summary
+	}
+	if description != "" {
+		printf("%s\n\n", description)
+	}
+	if len(cmd.Aliases) > 0 {
+		printf("%sALIASES:%s %s\n\n", ColorBold, ColorReset, strings.Join(cmd.Aliases, ", "))
+	}
+
+	printHelpSection("COMMANDS", ColorGreen, subcommandRows(cmd))
+	printHelpSection("OPTIONS", ColorCyan, flagRows(cmd.Flags))
+	printHelpSection("GLOBAL OPTIONS", ColorCyan, flagRows(globalFlags))
+	printHelpSection("EXAMPLES", ColorMagenta, exampleRows(cmd.Examples))
+
+	if len(cmd.VisibleSubcommands()) > 0 {
+		// Only the top-level help command exists, so the group path goes after it
+		printf("%sRun '%s <command>' for more information on a command.%s\n", ColorDim, strings.TrimSpace("xypcli help "+cmd.Path()), ColorReset)
+	}
+}
+
+// allCommands returns every visible command of the tree in depth-first order
+func allCommands(root *Command) []*Command {
+	commands := []*Command{}
+	for _, sub := range root.VisibleSubcommands() {
+		commands = append(commands, sub)
+		commands = append(commands, allCommands(sub)...)
+	}
+	return commands
+}
@@ -0,0 +1,847 @@
+package modules
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// modeFlag is the package manager selection shared by init, install and start
+var modeFlag = FlagDef{
+	Name:    "mode",
+	Type:    FlagString,
+	Value:   "<b|n>",
+	Usage:   "Installation mode: 'b' for bun, 'n' for npm",
+	Default: "auto",
+	Values:  []string{"b", "n"},
+}
+
+// globalFlags are accepted by every command
+var globalFlags = []FlagDef{
+	{Name: "output", Type: FlagString, Value: "<mode>", Usage: "Output mode: text, json (final report) or ndjson (event stream)", Default: "text", Values: []string{OutputText, OutputJSON, OutputNDJSON}},
+	{Name: "json", Type: FlagBool, Usage: "Shorthand for --output json"},
+	{Name: "no-color", Type: FlagBool, Usage: "Disable colors (also honors NO_COLOR, FORCE_COLOR and TERM=dumb)"},
+	{Name: "no-emoji", Type: FlagBool, Usage: "Plain ASCII output without emoji or box-drawing characters"},
+	{Name: "quiet", Short: "q", Type: FlagBool, Usage: "Only print errors, warnings and prompts"},
+	{Name: "profile", Type: FlagString, Value: "<name>", Usage: "Use a named profile of the CLI configuration (also XYPCLI_PROFILE)", Complete: settingsProfiles},
+	{Name: "help", Short: "h", Type: FlagBool, Usage: "Show help for the command"},
+}
+
+// settingsProfiles completes the profile names of the CLI configuration
+func settingsProfiles() []string {
+	settings, _ := LoadSettings("")
+	return settings.Profiles()
+}
+
+// licenseNames completes the licenses offered by the init wizard
+func licenseNames() []string {
+	names := []string{}
+	for _, option := range licenseOptions {
+		names = append(names, option.Value)
+	}
+	return names
+}
+
+// templateArchives completes the template zips of the current directory for init --template
+func templateArchives() []string {
+	names, _ := filepath.Glob("*.zip")
+	return names
+}
+
+// dependencyNames completes the dependencies and devDependencies of the project's package.json
+func dependencyNames() []string {
+	pkg, err := readPackageJSON(".")
+	if err != nil {
+		return nil
+	}
+	names := []string{}
+	for name := range pkg.Dependencies {
+		names = append(names, name)
+	}
+	for name := range pkg.DevDependencies {
+		if _, ok := pkg.Dependencies[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// envFlag selects the environment of init and start
+var envFlag = FlagDef{Name: "env", Type: FlagString, Value: "<env>", Usage: "Environment: selects the .env.<env> files and sets __sys__.__env__", Values: environments}
+
+// envFileFlags select the dotenv file read or changed by the env subcommands
+var envFileFlags = []FlagDef{
+	{Name: "file", Type: FlagString, Value: "<path>", Usage: "Dotenv file to read or change"},
+	{Name: "env", Type: FlagString, Value: "<env>", Usage: "Environment: its .env layers are read, and set/unset change .env.<env>"},
+}
+
+// envKeys completes the variables of .env and .env.example
+func envKeys() []string {
+	keys := []string{}
+	for _, name := range []string{".env", EnvExampleFile} {
+		if file, err := readDotenvFile(name); err == nil {
+			keys = append(keys, file.keys()...)
+		}
+	}
+	return keys
+}
+
+// secretsFlags select the encrypted file of the secrets subcommands
+var secretsFlags = []FlagDef{
+	{Name: "env", Type: FlagString, Value: "<env>", Usage: "Use .env.<env>.enc instead of .env.enc"},
+}
+
+// generateFlags are shared by the generators
+var generateFlags = []FlagDef{
+	{Name: "lang", Type: FlagString, Value: "<js|ts>", Usage: "Language of the generated file", Default: "project language", Values: []string{"js", "ts"}},
+	{Name: "force", Type: FlagBool, Usage: "Overwrite an existing file"},
+}
+
+// fieldsFlag lists the fields of a generated schema or resource
+var fieldsFlag = FlagDef{Name: "fields", Type: FlagString, Value: "<list>", Usage: "Fields as name:type pairs (string, number, boolean, email, url; ? for optional)"}
+
+// configTargetFlags select the file changed by "config set/unset/edit"
+var configTargetFlags = []FlagDef{
+	{Name: "project", Type: FlagBool, Usage: "Change the project file (" + ProjectSettingsFile + ") instead of the user file"},
+}
+
+// commandTree builds the registry of every xypcli command
+// Parsing, help, completion and docs are all generated from these definitions
+func (c *CLITool) commandTree() *Command {
+	root := &Command{
+		Name:    "xypcli",
+		Summary: "XyPriss command line interface",
+		MaxArgs: 0,
+		Flags: []FlagDef{
+			{Name: "version", Short: "v", Type: FlagBool, Usage: "Show CLI version"},
+		},
+		Examples: []Example{
+			{"xypcli init", "Interactive mode"},
+			{"xypcli init --name my-app --port 8080", "Quick init with options"},
+			{"xypcli init --name my-app --mode n", "Force npm installation"},
+			{"xypcli start", "Start development server"},
+			{"xypcli install xypriss cors", "Install multiple packages"},
+			{"xypcli install xypriss --mode b", "Install with bun"},
+			{"xypcli install cors --output ndjson", "Stream machine-readable events"},
+			{"xypcli help init", "Show the options of a command"},
+			{"xypcli completion install", "Enable shell completion"},
+			{"xypcli --version", "Show CLI version"},
+		},
+	}
+
+	root.Subcommands = []*Command{
+		{
+			Name:        "init",
+			Summary:     "Initialize a new XyPriss project with all necessary configuration",
+			Description: "Initialize a new XyPriss project: download the template, customize it and install its dependencies.\nOptions that are not given on the command line (or configured with 'xypcli config') are asked by an interactive wizard.",
+			Flags: []FlagDef{
+				{Name: "name", Type: FlagString, Value: "<name>", Usage: "Project name", Default: "interactive prompt"},
+				{Name: "description", Aliases: []string{"desc"}, Type: FlagString, Value: "<description>", Usage: "Project description"},
+				{Name: "lang", Aliases: []string{"language"}, Type: FlagString, Value: "<js|ts>", Usage: "Programming language", Default: "ts", Values: []string{"js", "ts"}},
+				{Name: "port", Type: FlagInt, Value: "<port>", Usage: "Server port", Default: "3000"},
+				// Only "--version" after "init" means the application version; "xypcli --version" is the CLI version
+				{Name: "app-version", Aliases: []string{"version"}, Type: FlagString, Value: "<version>", Usage: "Application version", Default: "1.0.0"},
+				{Name: "alias", Type: FlagString, Value: "<alias>", Usage: "Application alias", Default: "XyP"},
+				{Name: "author", Type: FlagString, Value: "<author>", Usage: "Author name", Default: "Nehonix-Team"},
+				{Name: "license", Type: FlagString, Value: "<spdx>", Usage: "License written to package.json (e.g. MIT)", Complete: licenseNames},
+				{Name: "template", Type: FlagString, Value: "<url|path>", Usage: "Template zip: a URL or a local path (defaults to the template setting)", Complete: templateArchives},
+				modeFlag,
+				envFlag,
+				{Name: "strict", Type: FlagBool, Usage: "Exit immediately if any package installation fails"},
+			},
+			Examples: []Example{
+				{"xypcli init", "Interactive mode"},
+				{"xypcli init --name my-app --port 8080", "Quick init with options"},
+				{"xypcli init --name my-api --desc \"My API\" --lang ts --app-version 0.1.0", "Non-interactive init"},
+				{"xypcli init --name my-app --mode n --strict", "Force npm and stop on the first failure"},
+			},
+			Run: func(c *CLITool, ctx *CommandContext) error {
+				if c.settingsErr != nil {
+					return c.settingsErr
+				}
+				// Configured defaults replace the interactive prompts; flags override both
+				return c.InitProject(InitFlags{
+					Name:        ctx.String("name"),
+					Description: c.flagOrSetting(ctx, "description", true),
+					Language:    c.flagOrSetting(ctx, "lang", true),
+					Port:        c.flagOrSetting(ctx, "port", true),
+					Version:     c.flagOrSetting(ctx, "app-version", true),
+					Alias:       c.flagOrSetting(ctx, "alias", true),
+					Author:      c.flagOrSetting(ctx, "author", true),
+					Mode:        c.flagOrSetting(ctx, "mode", false),
+					Strict:      c.flagOrSetting(ctx, "strict", false) == "true",
+					License:     ctx.String("license"),
+					Env:         ctx.String("env"),
+					Template:    c.flagOrSetting(ctx, "template", false),
+				})
+			},
+		},
+		{
+			Name:    "start",
+			Summary: "Start the XyPriss development server in the current directory",
+			Description: "Start the XyPriss development server in the current directory.\n" +
+				"The entry point is taken from --entry, the dev script of package.json, the \"script\" field of quickdev.config.json, the main field of package.json or a conventional file such as src/server.ts or src/index.js, in that order.\n" +
+				"TypeScript entries run with quickdev, bun or tsx; JavaScript entries with quickdev or node --watch.\n" +
+				"The package manager (bun, npm, pnpm or yarn) is detected from the packageManager field of package.json or the lockfile, and dependencies are reinstalled when the lockfile changed since the last install.\n" +
+				"The port (--port, then __sys__.__port__ in xypriss.config.json, then PORT from the .env files) is checked first; when it is taken, the process holding it is shown.\n" +
+				"Once the health endpoint (or the port) answers, the local, network, health and __sys__.__app_urls__ URLs are printed; the command fails if the server is not ready within --wait-timeout.\n" +
+				"The server is supervised: it is restarted after a crash with an exponential backoff (maxRestarts, resetRestartsAfter and restartDelay in quickdev.config.json), and Ctrl+C, SIGTERM and SIGHUP are forwarded to it, followed by SIGKILL after gracefulShutdownTimeout.\n" +
+				"The server gets the variables of .env, .env.enc, .env.<env>, .env.<env>.enc, .env.local and .env.<env>.local (encrypted files are decrypted in memory, later files win, the shell environment wins over all), where <env> comes from --env, __sys__.__env__, NODE_ENV or defaults to development.",
+			Flags: []FlagDef{
+				{Name: "entry", Type: FlagString, Value: "<file>", Usage: "Entry file to run (overrides package.json and quickdev.config.json)"},
+				modeFlag,
+				{Name: "port", Type: FlagString, Value: "<port|auto>", Usage: "Port passed to the server as PORT; 'auto' picks the next free port from __sys__.__port__", Complete: func() []string { return []string{"auto"} }},
+				{Name: "wait-timeout", Type: FlagString, Value: "<duration>", Usage: "How long to wait for the server to become ready before failing (e.g. 30s, 2m)", Default: "60s"},
+				{Name: "detach", Short: "d", Type: FlagBool, Usage: "Run in the background; output goes to " + RunDir + "/<name>.log"},
+				{Name: "all", Type: FlagBool, Usage: "Start every service of " + ServicesFile + " or the \"services\" of " + XyPrissConfigFile},
+				envFlag,
+				{Name: "print-env", Type: FlagBool, Usage: "Print the merged environment variables (secrets masked) and exit"},
+			},
+			Examples: []Example{
+				{"xypcli start", "Start development server"},
+				{"xypcli start --entry src/app.js", "Start a custom entry point"},
+				{"xypcli start --mode n", "Install and run with npm"},
+				{"xypcli start --port auto", "Use the next free port if the configured one is taken"},
+				{"xypcli start --wait-timeout 30s", "Fail if the server is not ready within 30 seconds"},
+				{"xypcli start --detach", "Start in the background"},
+				{"xypcli start --all", "Start every service of a multi-server project"},
+				{"xypcli start --env staging", "Load .env.staging and .env.staging.local on top of .env"},
+				{"xypcli start --env production --print-env", "Show the variables the server would get"},
+			},
+			Run: func(c *CLITool, ctx *CommandContext) error {
+				if c.settingsErr != nil {
+					return c.settingsErr
+				}
+				waitTimeout, err := parseWaitTimeout(ctx.String("wait-timeout"))
+				if err != nil {
+					return err
+				}
+				if ctx.Bool("all") {
+					for _, flag := range []string{"entry", "port", "mode", "detach", "env", "print-env"} {
+						if ctx.IsSet(flag) {
+							return usageError("--%s cannot be combined with --all", flag).
+								WithHint("Set \"command\", \"port\" and \"env\" of each service instead")
+						}
+					}
+					return c.StartServices(waitTimeout)
+				}
+				return c.StartServer(StartOptions{
+					Entry:       ctx.String("entry"),
+					Mode:        ctx.String("mode"),
+					Port:        ctx.String("port"),
+					WaitTimeout: waitTimeout,
+					Detach:      ctx.Bool("detach"),
+					Env:         ctx.String("env"),
+					PrintEnv:    ctx.Bool("print-env"),
+				})
+			},
+		},
+		{
+			Name:        "stop",
+			Summary:     "Stop background servers started with 'xypcli start --detach'",
+			Description: "Stop a background server of the current project.\nThe server gets its graceful shutdown timeout (gracefulShutdownTimeout in quickdev.config.json) before it is killed.",
+			Args:        "[name]",
+			MaxArgs:     1,
+			Flags: []FlagDef{
+				{Name: "all", Type: FlagBool, Usage: "Stop every background server of the project"},
+			},
+			Examples: []Example{
+				{"xypcli stop", "Stop the background server"},
+				{"xypcli stop --all", "Stop every background server"},
+			},
+			CompleteArgs: runNames,
+			Run: func(c *CLITool, ctx *CommandContext) error {
+				name := ""
+				if len(ctx.Args) > 0 {
+					name = ctx.Args[0]
+				}
+				return c.StopServers(name, ctx.Bool("all"))
+			},
+		},
+		{
+			Name:        "status",
+			Summary:     "Show the background servers of the current project",
+			Description: "Show the PID, port, uptime, health and memory of every background server of the current project.",
+			Examples: []Example{
+				{"xypcli status", "List background servers"},
+				{"xypcli status --json", "Machine-readable status"},
+			},
+			Run: func(c *CLITool, ctx *CommandContext) error {
+				return c.ServerStatus()
+			},
+		},
+		{
+			Name:        "logs",
+			Summary:     "Show the log of a background server",
+			Description: "Show the log of a background server of the current project.\nLogs are rotated at 10 MB; up to 3 older files (<name>.log.1 to .3) are kept and read first.",
+			Args:        "[name]",
+			MaxArgs:     1,
+			Flags: []FlagDef{
+				{Name: "follow", Short: "f", Type: FlagBool, Usage: "Keep printing new lines until Ctrl+C"},
+				{Name: "since", Type: FlagString, Value: "<time>", Usage: "Only show lines since a duration ago (10m, 2h) or a timestamp"},
+			},
+			Examples: []Example{
+				{"xypcli logs -f", "Follow the log"},
+				{"xypcli logs --since 10m", "Lines of the last 10 minutes"},
+			},
+			CompleteArgs: runNames,
+			Run: func(c *CLITool, ctx *CommandContext) error {
+				name := ""
+				if len(ctx.Args) > 0 {
+					name = ctx.Args[0]
+				}
+				return c.ShowLogs(name, ctx.Bool("follow"), ctx.String("since"))
+			},
+		},
+		{
+			Name:    "install",
+			Aliases: []string{"i"},
+			Summary: "Install one or more packages using the XyPriss installation system",
+			Description: "Install one or more packages into the current project.\n" +
+				"Several packages are installed in parallel (up to 4 at a time).",
+			Args:         "<package> [package...]",
+			MinArgs:      1,
+			MaxArgs:      -1,
+			Flags:        []FlagDef{modeFlag},
+			CompleteArgs: dependencyNames,
+			Examples: []Example{
+				{"xypcli install xypriss cors", "Install multiple packages"},
+				{"xypcli install xypriss --mode b", "Install with bun"},
+				{"xypcli install cors --output ndjson", "Stream machine-readable events"},
+			},
+			Run: func(c *CLITool, ctx *CommandContext) error {
+				if c.settingsErr != nil {
+					return c.settingsErr
+				}
+				return c.InstallPackages(ctx.Args, c.flagOrSetting(ctx, "mode", false))
+			},
+		},
+		{
+			Name:    "version",
+			Summary: "Show CLI version information",
+			Run: func(c *CLITool, ctx *CommandContext) error {
+				resultf("XyPCLI v%s\n", c.version)
+				return nil
+			},
+		},
+		{
+			Name:    "help",
+			Summary: "Show help for xypcli or one of its commands",
+			Args:    "[command...]",
+			MaxArgs: -1,
+			Examples: []Example{
+				{"xypcli help", "Show the list of commands"},
+				{"xypcli help install", "Show the options of the install command"},
+			},
+			CompleteArgs: func() []string {
+				return commandNames(root)
+			},
+			Run: func(c *CLITool, ctx *CommandContext) error {
+				cmd := root
+				for _, name := range ctx.Args {
+					sub := cmd.findSubcommand(name)
+					if sub == nil {
+						return unknownCommandError(cmd, name)
+					}
+					cmd = sub
+				}
+				c.showCommandHelp(cmd)
+				return nil
+			},
+		},
+		{
+			Name:    "completion",
+			Summary: "Generate shell completion scripts for bash, zsh and fish",
+			Description: "Print the completion script for a shell, or install it with 'xypcli completion install'.\n" +
+				"Commands, flags and flag values (--mode, --lang, ...) are completed.",
+			Args:    "<bash|zsh|fish>",
+			MinArgs: 1,
+			MaxArgs: 1,
+			Examples: []Example{
+				{"xypcli completion install", "Install completion for the current shell"},
+				{"source <(xypcli completion bash)", "Enable completion in the current bash session"},
+				{"xypcli completion fish > ~/.config/fish/completions/xypcli.fish", "Install fish completion manually"},
+			},
+			CompleteArgs: func() []string {
+				return completionShells
+			},
+			Run: func(c *CLITool, ctx *CommandContext) error {
+				return c.PrintCompletionScript(ctx.Args[0])
+			},
+			Subcommands: []*Command{
+				{
+					Name:    "install",
+					Summary: "Install the completion script for your shell",
+					Description: "Write the completion script where the shell loads it automatically.\n" +
+						"The shell is detected from $SHELL unless given explicitly.",
+					Args:    "[bash|zsh|fish]",
+					MaxArgs: 1,
+					CompleteArgs: func() []string {
+						return completionShells
+					},
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						shell := ""
+						if len(ctx.Args) > 0 {
+							shell = ctx.Args[0]
+						}
+						return c.InstallCompletion(shell)
+					},
+				},
+			},
+		},
+		{
+			Name:    "config",
+			Summary: "Manage CLI defaults and profiles",
+			Description: "Manage the CLI configuration: defaults for init, package manager mode, concurrency, template, registry and colors.\n" +
+				"Values are resolved with the precedence flag > env (XYPCLI_*) > project (" + ProjectSettingsFile + ") > user (~/.config/xypcli/config.json) > built-in.\n" +
+				"Named profiles group values that are only used with --profile <name> or XYPCLI_PROFILE.",
+			Examples: []Example{
+				{"xypcli config set author \"Jane Doe\"", "Default author for new projects"},
+				{"xypcli config set mode n --project", "Always install with npm in this project"},
+				{"xypcli config set registry https://npm.example.com --profile work", "Registry of the work profile"},
+				{"xypcli init --profile work", "Use the work profile"},
+				{"xypcli config list", "Show every setting and where it comes from"},
+				{"xypcli config validate", "Check " + XyPrissConfigFile},
+			},
+			Subcommands: []*Command{
+				{
+					Name:         "get",
+					Summary:      "Print the resolved value of a setting",
+					Args:         "<key>",
+					MinArgs:      1,
+					MaxArgs:      1,
+					CompleteArgs: settingNames,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.ConfigGet(ctx.Args[0])
+					},
+				},
+				{
+					Name:         "set",
+					Summary:      "Store a setting in the user or project configuration",
+					Description:  "Store a setting in the user configuration, or in " + ProjectSettingsFile + " with --project.\nWith --profile the value is stored in that profile.",
+					Args:         "<key> <value>",
+					MinArgs:      2,
+					MaxArgs:      2,
+					Flags:        configTargetFlags,
+					CompleteArgs: settingNames,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.ConfigSet(ctx.Args[0], ctx.Args[1], ctx.String("profile"), ctx.Bool("project"))
+					},
+				},
+				{
+					Name:         "unset",
+					Summary:      "Remove a setting from the user or project configuration",
+					Args:         "<key>",
+					MinArgs:      1,
+					MaxArgs:      1,
+					Flags:        configTargetFlags,
+					CompleteArgs: settingNames,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.ConfigUnset(ctx.Args[0], ctx.String("profile"), ctx.Bool("project"))
+					},
+				},
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Summary: "Show every setting with its value and its source",
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.ConfigList()
+					},
+				},
+				{
+					Name:    "validate",
+					Summary: "Check " + XyPrissConfigFile + " against its schema and the project",
+					Description: "Check " + XyPrissConfigFile + ": wrong types and out-of-range ports are errors; unknown keys, and a name, version, description or port that differs from package.json or .env, are warnings.\n" +
+						"Each problem is reported with the JSON pointer of the value (e.g. /__sys__/__port__). The command fails on errors, and on warnings too with --strict.",
+					Flags: []FlagDef{
+						{Name: "file", Type: FlagString, Value: "<path>", Usage: "File to check", Default: XyPrissConfigFile},
+						{Name: "strict", Type: FlagBool, Usage: "Fail on warnings too"},
+					},
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.ConfigValidate(orDefault(ctx.String("file"), XyPrissConfigFile), ctx.Bool("strict"))
+					},
+				},
+				{
+					Name:        "schema",
+					Summary:     "Write the JSON Schema of " + XyPrissConfigFile + " for editors",
+					Description: "Write the JSON Schema of " + XyPrissConfigFile + ", generated from the schema used by 'config validate'. Reference it with \"$schema\" in the file so that editors complete and check it.",
+					Flags: []FlagDef{
+						{Name: "out", Type: FlagString, Value: "<file>", Usage: "Output file, or - for stdout", Default: ConfigSchemaFile},
+					},
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.ConfigSchema(orDefault(ctx.String("out"), ConfigSchemaFile))
+					},
+				},
+				{
+					Name:    "edit",
+					Summary: "Open the user or project configuration in $VISUAL / $EDITOR",
+					Flags:   configTargetFlags,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.ConfigEdit(ctx.Bool("project"))
+					},
+				},
+			},
+		},
+		{
+			Name:    "env",
+			Summary: "Read and change the variables of the project's .env files",
+			Description: "Read and change the project's dotenv files without losing their comments, order or quoting.\n" +
+				"set and unset change .env (or .env.<env> with --env, or --file), and keep " + EnvExampleFile + " in sync: it lists every key, without values.\n" +
+				"Keys of " + EnvExampleFile + " are required, unless the comment line above them contains \"optional\"; list and check report the missing ones.",
+			Examples: []Example{
+				{"xypcli env set DATABASE_URL postgres://localhost/app", "Set a variable in .env"},
+				{"xypcli env set LOG_LEVEL warn --env production", "Set a variable in .env.production"},
+				{"xypcli env get PORT", "Print the value the server would get"},
+				{"xypcli env list --env staging", "Show the merged staging variables"},
+				{"xypcli env diff staging production", "Compare .env.staging and .env.production"},
+				{"xypcli env check --env production", "Fail if a required variable is missing"},
+			},
+			Subcommands: []*Command{
+				{
+					Name:         "get",
+					Summary:      "Print the value of a variable",
+					Description:  "Print the value of a variable, merged from the .env layers of the environment (the shell environment wins), or read from --file.",
+					Args:         "<key>",
+					MinArgs:      1,
+					MaxArgs:      1,
+					Flags:        envFileFlags,
+					CompleteArgs: envKeys,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.EnvGet(ctx.Args[0], ctx.String("file"), ctx.String("env"))
+					},
+				},
+				{
+					Name:         "set",
+					Summary:      "Set a variable in a dotenv file",
+					Description:  "Set a variable in .env, .env.<env> with --env, or --file. An existing assignment is changed in place; a new key is appended and listed in " + EnvExampleFile + ".",
+					Args:         "<key> <value>",
+					MinArgs:      2,
+					MaxArgs:      2,
+					Flags:        envFileFlags,
+					CompleteArgs: envKeys,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.EnvSet(ctx.Args[0], ctx.Args[1], ctx.String("file"), ctx.String("env"))
+					},
+				},
+				{
+					Name:         "unset",
+					Summary:      "Remove a variable from a dotenv file",
+					Description:  "Remove a variable from .env, .env.<env> with --env, or --file. The key leaves " + EnvExampleFile + " once no dotenv file sets it.",
+					Args:         "<key>",
+					MinArgs:      1,
+					MaxArgs:      1,
+					Flags:        envFileFlags,
+					CompleteArgs: envKeys,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.EnvUnset(ctx.Args[0], ctx.String("file"), ctx.String("env"))
+					},
+				},
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Summary: "Show the variables with their source, secrets masked",
+					Flags: append([]FlagDef{
+						{Name: "reveal", Type: FlagBool, Usage: "Show secret values"},
+					}, envFileFlags...),
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.EnvList(ctx.String("file"), ctx.String("env"), ctx.Bool("reveal"))
+					},
+				},
+				{
+					Name:        "diff",
+					Summary:     "Compare two dotenv files key by key",
+					Description: "Compare two dotenv files key by key. Each argument is a path or an environment name (staging means .env.staging); secrets are masked unless --reveal is given.",
+					Args:        "<file|env> <file|env>",
+					MinArgs:     2,
+					MaxArgs:     2,
+					Flags: []FlagDef{
+						{Name: "reveal", Type: FlagBool, Usage: "Show secret values"},
+					},
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.EnvDiff(ctx.Args[0], ctx.Args[1], ctx.Bool("reveal"))
+					},
+				},
+				{
+					Name:    "check",
+					Summary: "Fail if a required variable of " + EnvExampleFile + " is missing",
+					Flags: []FlagDef{
+						{Name: "env", Type: FlagString, Value: "<env>", Usage: "Environment whose .env layers are checked"},
+					},
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.EnvCheck(ctx.String("env"))
+					},
+				},
+			},
+		},
+		{
+			Name:    "secrets",
+			Summary: "Keep the project's secret variables in an encrypted .env.enc",
+			Description: "Keep secret variables in .env.enc (or .env.<env>.enc with --env), encrypted with NaCl secretbox, so that they can be committed.\n" +
+				"The key is read from " + secretsKeyEnv + " or " + SecretsKeyFile + " (created by 'secrets init' and ignored by git).\n" +
+				"'xypcli start' decrypts the files in memory: .env.enc is loaded after .env, and .env.<env>.enc after .env.<env>.",
+			Examples: []Example{
+				{"xypcli secrets init", "Create the key and an empty .env.enc"},
+				{"xypcli secrets set STRIPE_KEY sk_live_...", "Store a secret"},
+				{"xypcli secrets set DATABASE_URL postgres://... --env production", "Store a secret in .env.production.enc"},
+				{"xypcli secrets edit", "Edit the decrypted file in $EDITOR"},
+				{"XYPCLI_SECRETS_KEY=... xypcli start", "Run with the key from the environment (e.g. in CI)"},
+			},
+			Subcommands: []*Command{
+				{
+					Name:    "init",
+					Summary: "Create the secrets key and an empty encrypted file",
+					Flags:   secretsFlags,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.SecretsInit(ctx.String("env"))
+					},
+				},
+				{
+					Name:    "set",
+					Summary: "Store a variable in the encrypted file",
+					Args:    "<key> <value>",
+					MinArgs: 2,
+					MaxArgs: 2,
+					Flags:   secretsFlags,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.SecretsSet(ctx.Args[0], ctx.Args[1], ctx.String("env"))
+					},
+				},
+				{
+					Name:    "get",
+					Summary: "Print a variable of the encrypted file",
+					Args:    "<key>",
+					MinArgs: 1,
+					MaxArgs: 1,
+					Flags:   secretsFlags,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.SecretsGet(ctx.Args[0], ctx.String("env"))
+					},
+				},
+				{
+					Name:        "edit",
+					Summary:     "Edit the decrypted file in $VISUAL / $EDITOR",
+					Description: "Decrypt the file into a private temporary file, open it in $VISUAL / $EDITOR, then encrypt the result and delete the temporary file.",
+					Flags:       secretsFlags,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.SecretsEdit(ctx.String("env"))
+					},
+				},
+				{
+					Name:    "decrypt",
+					Summary: "Print the decrypted file, or write it with --out",
+					Flags: append([]FlagDef{
+						{Name: "out", Type: FlagString, Value: "<file>", Usage: "Write the plaintext to this file (mode 0600) instead of printing it"},
+					}, secretsFlags...),
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.SecretsDecrypt(ctx.String("env"), ctx.String("out"))
+					},
+				},
+			},
+		},
+		{
+			Name:    "sys",
+			Summary: "Read and change the __sys__ values of " + XyPrissConfigFile,
+			Description: "Read and change the __sys__ section of " + XyPrissConfigFile + " (name, version, description, author, alias, port, env) and its app URLs.\n" +
+				"set also updates every copy of the value: package.json name, version and description, __PORT__ and the PORT of .env.\n" +
+				"Only the changed values are rewritten: key order, indentation and the rest of the files are kept as they are.",
+			Examples: []Example{
+				{"xypcli sys get", "Show every __sys__ value"},
+				{"xypcli sys set port 8080", "Change __port__, __PORT__ and the PORT of .env"},
+				{"xypcli sys set version 1.2.0", "Change __version__ and the version of package.json"},
+				{"xypcli sys url add api https://api.example.com", "Add an entry to __app_urls__"},
+				{"xypcli sys url rm api", "Remove it"},
+			},
+			Subcommands: []*Command{
+				{
+					Name:         "get",
+					Summary:      "Print a __sys__ value, or all of them",
+					Args:         "[key]",
+					MaxArgs:      1,
+					CompleteArgs: sysKeyNames,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						name := ""
+						if len(ctx.Args) > 0 {
+							name = ctx.Args[0]
+						}
+						return c.SysGet(name)
+					},
+				},
+				{
+					Name:         "set",
+					Summary:      "Change a __sys__ value and its copies in package.json and .env",
+					Description:  "Change a __sys__ value. Keys: name, version, description, author, alias, port, env (the __key__ form is accepted too).\nname, version and description are also written to package.json; port to __PORT__ and the PORT of .env.",
+					Args:         "<key> <value>",
+					MinArgs:      2,
+					MaxArgs:      2,
+					CompleteArgs: sysKeyNames,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.SysSet(ctx.Args[0], ctx.Args[1])
+					},
+				},
+				{
+					Name:    "url",
+					Summary: "Manage the entries of __sys__.__app_urls__",
+					Subcommands: []*Command{
+						{
+							Name:        "add",
+							Summary:     "Add or change an app URL",
+							Description: "Add or change an entry of __sys__.__app_urls__. The URL is an http(s) URL or a path starting with /.",
+							Args:        "<name> <url>",
+							MinArgs:     2,
+							MaxArgs:     2,
+							Run: func(c *CLITool, ctx *CommandContext) error {
+								return c.SysURLAdd(ctx.Args[0], ctx.Args[1])
+							},
+						},
+						{
+							Name:         "rm",
+							Aliases:      []string{"remove"},
+							Summary:      "Remove an app URL",
+							Args:         "<name>",
+							MinArgs:      1,
+							MaxArgs:      1,
+							CompleteArgs: appURLNames,
+							Run: func(c *CLITool, ctx *CommandContext) error {
+								return c.SysURLRemove(ctx.Args[0])
+							},
+						},
+						{
+							Name:    "list",
+							Aliases: []string{"ls"},
+							Summary: "Show the app URLs",
+							Run: func(c *CLITool, ctx *CommandContext) error {
+								return c.SysURLList()
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:    "generate",
+			Aliases: []string{"g"},
+			Summary: "Generate routes, middleware, schemas and CRUD resources from templates",
+			Description: "Generate source files in TypeScript or JavaScript, following the language of the project (tsconfig.json means TypeScript).\n" +
+				"Routes are mounted in " + RoutesIndexFile + ".ts and middleware is added to setupMiddleware in " + MiddlewareIndexFile + ".ts; running a generator again does not register twice.\n" +
+				"A template in " + GeneratorsDir + "/<kind>.<lang>.tmpl (e.g. route.ts.tmpl) replaces the built-in one; 'generate templates' writes the built-in templates there as a starting point.",
+			Examples: []Example{
+				{"xypcli generate route users --methods GET,POST", "Create src/routes/users.route.ts, mounted on /users"},
+				{"xypcli generate route health-check --path /healthz", "Mount on a custom path"},
+				{"xypcli generate middleware request-timer", "Create src/middleware/request-timer.middleware.ts"},
+				{"xypcli generate schema product --fields \"name:string,price:number\"", "Create src/schema/product.schema.ts"},
+				{"xypcli generate resource todo --fields \"title:string,done:bool\"", "Create a CRUD resource mounted on /todos"},
+				{"xypcli generate templates", "Copy the built-in templates to " + GeneratorsDir},
+			},
+			Subcommands: []*Command{
+				{
+					Name:        "route",
+					Summary:     "Create a router and mount it in " + RoutesIndexFile,
+					Description: "Create src/routes/<name>.route.ts (or .js) with a handler for each method, and mount it in " + RoutesIndexFile + " on --path (/<name> by default).",
+					Args:        "<name>",
+					MinArgs:     1,
+					MaxArgs:     1,
+					Flags: append([]FlagDef{
+						{Name: "methods", Type: FlagString, Value: "<list>", Usage: "Comma-separated HTTP methods", Default: "GET"},
+						{Name: "path", Type: FlagString, Value: "<path>", Usage: "Mount path", Default: "/<name>"},
+					}, generateFlags...),
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.GenerateRoute(ctx.Args[0], ctx.String("methods"), ctx.String("path"), ctx.String("lang"), ctx.Bool("force"))
+					},
+				},
+				{
+					Name:        "middleware",
+					Summary:     "Create a middleware and register it in setupMiddleware",
+					Description: "Create src/middleware/<name>.middleware.ts (or .js) and add it with app.use() to setupMiddleware in " + MiddlewareIndexFile + ".",
+					Args:        "<name>",
+					MinArgs:     1,
+					MaxArgs:     1,
+					Flags:       generateFlags,
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.GenerateMiddleware(ctx.Args[0], ctx.String("lang"), ctx.Bool("force"))
+					},
+				},
+				{
+					Name:        "schema",
+					Summary:     "Create a fortify-schema interface",
+					Description: "Create src/schema/<name>.schema.ts (or .js) exporting <name>Schema, to validate requests with validateBody().",
+					Args:        "<name>",
+					MinArgs:     1,
+					MaxArgs:     1,
+					Flags:       append([]FlagDef{fieldsFlag}, generateFlags...),
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.GenerateSchema(ctx.Args[0], ctx.String("fields"), ctx.String("lang"), ctx.Bool("force"))
+					},
+				},
+				{
+					Name:    "resource",
+					Summary: "Create a CRUD resource: schema, validation, repository, router and tests",
+					Description: "Create the files of a CRUD resource: src/schema/<name>.schema, src/middleware/<name>-validation.middleware, src/repositories/<name>.repository (in memory, replaceable with use<Name>Repository()), src/routes/<name>.route and tests/<name>.test.\n" +
+						"The router has list, get, create, update and delete handlers; it is mounted in " + RoutesIndexFile + " on --path (the plural of the name by default, e.g. /todos).",
+					Args:    "<name>",
+					MinArgs: 1,
+					MaxArgs: 1,
+					Flags: append([]FlagDef{
+						fieldsFlag,
+						{Name: "path", Type: FlagString, Value: "<path>", Usage: "Mount path", Default: "/<plural of name>"},
+					}, generateFlags...),
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.GenerateResource(ctx.Args[0], ctx.String("fields"), ctx.String("path"), ctx.String("lang"), ctx.Bool("force"))
+					},
+				},
+				{
+					Name:    "templates",
+					Summary: "Copy the built-in templates to " + GeneratorsDir + " to customize them",
+					Flags: []FlagDef{
+						{Name: "force", Type: FlagBool, Usage: "Overwrite existing templates"},
+					},
+					Run: func(c *CLITool, ctx *CommandContext) error {
+						return c.GenerateTemplates(ctx.Bool("force"))
+					},
+				},
+			},
+		},
+		{
+			Name:    "docs",
+			Summary: "Generate man pages or Markdown reference docs for every command",
+			Description: "Generate the reference documentation from the command definitions used for parsing and help.\n" +
+				"With --check nothing is written: the command fails if the docs in the output directory are out of date.",
+			Flags: []FlagDef{
+				{Name: "format", Type: FlagString, Value: "<man|md>", Usage: "Documentation format", Default: "md", Values: []string{DocsMan, DocsMarkdown}},
+				{Name: "out", Type: FlagString, Value: "<dir>", Usage: "Output directory", Default: "docs/<format>"},
+				{Name: "check", Type: FlagBool, Usage: "Fail if the docs in the output directory differ from the generated ones"},
+			},
+			Examples: []Example{
+				{"xypcli docs --format man --out /usr/local/share/man/man1", "Install the man pages"},
+				{"xypcli docs --format md", "Regenerate docs/md"},
+				{"xypcli docs --format md --check", "Verify that docs/md is up to date"},
+			},
+			Run: func(c *CLITool, ctx *CommandContext) error {
+				format := ctx.String("format")
+				if format == "" {
+					format = DocsMarkdown
+				}
+				out := ctx.String("out")
+				if out == "" {
+					out = filepath.Join("docs", format)
+				}
+				return c.GenerateDocs(format, out, ctx.Bool("check"))
+			},
+		},
+		{
+			// Called by the completion scripts: prints the candidates for the word being typed
+			Name:    "__complete",
+			Hidden:  true,
+			Args:    "[word...]",
+			MaxArgs: -1,
+			Flags: []FlagDef{
+				{Name: "shell", Type: FlagString, Value: "<shell>", Usage: "Output format", Default: "bash", Values: completionShells},
+			},
+			Run: func(c *CLITool, ctx *CommandContext) error {
+				c.PrintCompletions(ctx.String("shell"), ctx.Args)
+				return nil
+			},
+		},
+	}
+
+	return root.link()
+}
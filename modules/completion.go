@@ -0,0 +1,256 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Supported shells for completion scripts
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// completionScripts are the shell scripts printed by "xypcli completion <shell>"
+// They delegate to the hidden "xypcli __complete" command, so the candidates always
+// match the command registry of the installed binary
+var completionScripts = map[string]string{
+	"bash": `# bash completion for xypcli
+_xypcli() {
+    local IFS=$'\n'
+    COMPREPLY=($(xypcli __complete --shell bash -- "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _xypcli xypcli
+`,
+	"zsh": `#compdef xypcli
+# zsh completion for xypcli
+_xypcli() {
+    local -a candidates
+    candidates=("${(@f)$(xypcli __complete --shell zsh -- "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    _describe 'xypcli' candidates
+}
+if [ "$funcstack[1]" = "_xypcli" ]; then
+    _xypcli "$@"
+else
+    compdef _xypcli xypcli
+fi
+`,
+	"fish": `# fish completion for xypcli
+complete -c xypcli -f -a '(xypcli __complete --shell fish -- (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null)'
+`,
+}
+
+// completionCandidate is one value offered by shell completion
+type completionCandidate struct {
+	Value       string
+	Description string
+}
+
+// PrintCompletionScript prints the completion script for a shell
+func (c *CLITool) PrintCompletionScript(shell string) error {
+	script, ok := completionScripts[shell]
+	if !ok {
+		return unsupportedShellError(shell)
+	}
+	resultf("%s", script)
+	return nil
+}
+
+// InstallCompletion writes the completion script where the shell loads it automatically
+// The shell is detected from $SHELL unless given explicitly
+func (c *CLITool) InstallCompletion(shell string) error {
+	if shell == "" {
+		shell = filepath.Base(os.Getenv("SHELL"))
+		if _, ok := completionScripts[shell]; !ok {
+			return environmentError("could not detect your shell from $SHELL (%s)", os.Getenv("SHELL")).
+				WithHint("Run 'xypcli completion install <bash|zsh|fish>'")
+		}
+	}
+	script, ok := completionScripts[shell]
+	if !ok {
+		return unsupportedShellError(shell)
+	}
+
+	path, err := completionPath(shell)
+	if err != nil {
+		return environmentError("could not locate your home directory: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return failureError(err, "failed to create %s", filepath.Dir(path))
+	}
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		return failureError(err, "failed to write %s", path)
+	}
+
+	printf("%s✓ Installed %s completion to %s%s\n", ColorGreen, shell, path, ColorReset)
+	switch shell {
+	case "bash":
+		printf("  %sRequires the bash-completion package; open a new shell to use it%s\n", ColorDim, ColorReset)
+	case "zsh":
+		printf("  %sAdd this to your ~/.zshrc if completion does not load:%s\n", ColorDim, ColorReset)
+		printf("    fpath=(%s $fpath)\n", filepath.Dir(path))
+		printf("    autoload -U compinit && compinit\n")
+	case "fish":
+		printf("  %sOpen a new shell to use it%s\n", ColorDim, ColorReset)
+	}
+	return nil
+}
+
+// completionPath returns the per-user completion file for a shell
+func completionPath(shell string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch shell {
+	case "bash":
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if dataHome == "" {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+		return filepath.Join(dataHome, "bash-completion", "completions", "xypcli"), nil
+	case "zsh":
+		return filepath.Join(home, ".zsh", "completions", "_xypcli"), nil
+	default:
+		configHome := os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		return filepath.Join(configHome, "fish", "completions", "xypcli.fish"), nil
+	}
+}
+
+// unsupportedShellError reports a shell without a completion script
+func unsupportedShellError(shell string) *CLIError {
+	err := usageError("unsupported shell '%s' (expected %s)", shell, strings.Join(completionShells, ", "))
+	if suggestion := suggest(shell, completionShells); suggestion != "" {
+		return err.WithHint("Did you mean '%s'?", suggestion)
+	}
+	return err
+}
+
+// PrintCompletions prints the completion candidates for a partial command line
+// words are the arguments after "xypcli"; the last one is the word being completed
+func (c *CLITool) PrintCompletions(shell string, words []string) {
+	for _, candidate := range completeWords(c.commandTree(), globalFlags, words) {
+		switch {
+		case shell == "zsh" && candidate.Description != "":
+			fmt.Fprintf(os.Stdout, "%s:%s\n", strings.ReplaceAll(candidate.Value, ":", `\:`), candidate.Description)
+		case shell == "zsh":
+			fmt.Fprintln(os.Stdout, strings.ReplaceAll(candidate.Value, ":", `\:`))
+		case shell == "fish" && candidate.Description != "":
+			fmt.Fprintf(os.Stdout, "%s\t%s\n", candidate.Value, candidate.Description)
+		default:
+			fmt.Fprintln(os.Stdout, candidate.Value)
+		}
+	}
+}
+
+// completeWords resolves the command being typed and returns the candidates for the last word
+func completeWords(root *Command, globalFlags []FlagDef, words []string) []completionCandidate {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	// bash splits "--mode=b" into "--mode", "=", "b": treat it as "--mode b"
+	normalized := []string{}
+	for i, word := range words {
+		if word == "=" && i > 0 && strings.HasPrefix(words[i-1], "--") {
+			if i == len(words)-1 {
+				normalized = append(normalized, "")
+			}
+			continue
+		}
+		normalized = append(normalized, word)
+	}
+	words = normalized
+
+	cmd := root
+	positional := 0
+	var pending *FlagDef // Flag waiting for its value
+	flagsOnly := false
+	for _, word := range words[:len(words)-1] {
+		available := append(append([]FlagDef{}, cmd.Flags...), globalFlags...)
+		switch {
+		case pending != nil:
+			pending = nil
+		case word == "--" && !flagsOnly:
+			flagsOnly = true
+		case strings.HasPrefix(word, "--") && !flagsOnly:
+			name, _, hasValue := strings.Cut(word[2:], "=")
+			if flag, ok := lookupFlag(available, name, false); ok && flag.Type != FlagBool && !hasValue {
+				pending = &flag
+			}
+		case strings.HasPrefix(word, "-") && len(word) == 2 && !flagsOnly:
+			if flag, ok := lookupFlag(available, word[1:], true); ok && flag.Type != FlagBool {
+				pending = &flag
+			}
+		default:
+			if positional == 0 && !flagsOnly {
+				if sub := cmd.findSubcommand(word); sub != nil {
+					cmd = sub
+					continue
+				}
+			}
+			positional++
+		}
+	}
+
+	current := words[len(words)-1]
+	available := append(append([]FlagDef{}, cmd.Flags...), globalFlags...)
+	candidates := []completionCandidate{}
+
+	switch {
+	case pending != nil:
+		candidates = flagValueCandidates(*pending, "")
+	case strings.HasPrefix(current, "--") && strings.Contains(current, "=") && !flagsOnly:
+		name, _, _ := strings.Cut(current[2:], "=")
+		if flag, ok := lookupFlag(available, name, false); ok {
+			candidates = flagValueCandidates(flag, "--"+name+"=")
+		}
+	case strings.HasPrefix(current, "-") && !flagsOnly:
+		for _, flag := range available {
+			candidates = append(candidates, completionCandidate{"--" + flag.Name, flag.Usage})
+		}
+	default:
+		if positional == 0 {
+			for _, sub := range cmd.VisibleSubcommands() {
+				candidates = append(candidates, completionCandidate{sub.Name, sub.Summary})
+			}
+		}
+		if cmd.CompleteArgs != nil && (cmd.MaxArgs < 0 || positional < cmd.MaxArgs) {
+			for _, value := range cmd.CompleteArgs() {
+				candidates = append(candidates, completionCandidate{Value: value})
+			}
+		}
+	}
+
+	matches := []completionCandidate{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate.Value, current) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+// flagValueCandidates returns the values a flag accepts, with an optional prefix (e.g. "--mode=")
+func flagValueCandidates(flag FlagDef, prefix string) []completionCandidate {
+	values := flag.Values
+	if flag.Complete != nil {
+		values = append(append([]string{}, values...), flag.Complete()...)
+	}
+	candidates := []completionCandidate{}
+	for _, value := range values {
+		candidates = append(candidates, completionCandidate{Value: prefix + value})
+	}
+	return candidates
+}
+
+// commandNames returns the names of the visible subcommands of a command
+func commandNames(cmd *Command) []string {
+	names := []string{}
+	for _, sub := range cmd.VisibleSubcommands() {
+		names = append(names, sub.Name)
+	}
+	return names
+}
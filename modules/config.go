@@ -1,10 +1,10 @@
 package modules
 
 import (
-	"bufio"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -24,178 +24,361 @@ type ProjectConfig struct {
 	WithAuth     bool   // Include JWT authentication system
 	WithUpload   bool   // Include file upload functionality with multer
 	WithMulti    bool   // Include multi-server configuration
+	Mode         string // Package manager: "b" (bun), "n" (npm) or "" (auto)
+	License      string // SPDX license written to package.json ("" keeps the template's)
+	Env          string // Environment written to __sys__.__env__ (defaults to "development")
 }
 
-// handleExistingDirectory checks if a directory exists and handles the case where it's not empty
-// Returns true if we can proceed with the directory name, false if user wants to choose another name
-func handleExistingDirectory(dirName string, reader *bufio.Reader) bool {
-	if _, err := os.Stat(dirName); os.IsNotExist(err) {
-		// Directory doesn't exist, we can proceed
-		return true
+// Choices offered by the init wizard
+var (
+	languageOptions = []Option{
+		{Label: "TypeScript", Value: "ts"},
+		{Label: "JavaScript", Value: "js"},
 	}
+	packageManagerOptions = []Option{
+		{Label: "Auto", Value: "", Hint: "bun when available, npm otherwise"},
+		{Label: "Bun", Value: "b"},
+		{Label: "npm", Value: "n"},
+	}
+	featureOptions = []Option{
+		{Label: "Authentication", Value: "auth", Hint: "JWT-based authentication"},
+		{Label: "File Upload", Value: "upload", Hint: "multer"},
+		{Label: "Multi-Server", Value: "multi", Hint: "multiple server instances"},
+	}
+	licenseOptions = []Option{
+		{Label: "MIT", Value: "MIT"},
+		{Label: "ISC", Value: "ISC"},
+		{Label: "Apache-2.0", Value: "Apache-2.0"},
+		{Label: "GPL-3.0-only", Value: "GPL-3.0-only"},
+		{Label: "BSD-3-Clause", Value: "BSD-3-Clause"},
+		{Label: "UNLICENSED", Value: "UNLICENSED", Hint: "proprietary"},
+	}
+)
 
-	// Directory exists, check if it's empty
-	files, err := ioutil.ReadDir(dirName)
-	if err != nil {
-		fmt.Printf("%sError reading directory: %v%s\n", ColorRed, err, ColorReset)
-		return false
+// versionPattern accepts semantic versions such as 1.0.0 or 2.1.0-beta.1
+var versionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// validateProjectName checks that a project name can be used as a directory name
+func validateProjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", fmt.Errorf("the project name cannot be empty")
+	case name == "." || name == "..":
+		return "", fmt.Errorf("'%s' is not a valid project name", name)
+	case strings.ContainsAny(name, `/\:*?"<>|`):
+		return "", fmt.Errorf("the project name cannot contain any of / \\ : * ? \" < > |")
 	}
+	return name, nil
+}
 
-	if len(files) == 0 {
-		// Directory exists but is empty, we can proceed
-		return true
+// validateLanguage accepts js/ts and their full names
+func validateLanguage(language string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(language)) {
+	case "ts", "typescript":
+		return "ts", nil
+	case "js", "javascript":
+		return "js", nil
 	}
+	return "", fmt.Errorf("unknown language '%s' (expected js or ts)", language)
+}
 
-	// Directory exists and is not empty, ask user what to do
-	fmt.Printf("\n%s⚠ Directory '%s' already exists and is not empty.%s\n", ColorYellow, dirName, ColorReset)
-	fmt.Printf("%sWhat would you like to do?%s\n", ColorBold, ColorReset)
-	fmt.Printf("  %s1.%s Delete the directory and create a new project\n", ColorCyan, ColorReset)
-	fmt.Printf("  %s2.%s Choose a different project name\n", ColorCyan, ColorReset)
-	fmt.Printf("%sEnter your choice (1 or 2):%s ", ColorBold, ColorReset)
+// validatePort accepts a TCP port number
+func validatePort(port string) (string, error) {
+	number, err := strconv.Atoi(strings.TrimSpace(port))
+	if err != nil || number < 1 || number > 65535 {
+		return "", fmt.Errorf("'%s' is not a valid port (expected a number between 1 and 65535)", port)
+	}
+	return strconv.Itoa(number), nil
+}
 
-	choice, _ := reader.ReadString('\n')
-	choice = strings.TrimSpace(choice)
+// validateVersion accepts a semantic version
+func validateVersion(version string) (string, error) {
+	version = strings.TrimSpace(version)
+	if !versionPattern.MatchString(version) {
+		return "", fmt.Errorf("'%s' is not a valid version (expected e.g. 1.0.0)", version)
+	}
+	return version, nil
+}
 
-	switch choice {
-	case "1":
-		fmt.Printf("%s🗑️  Deleting existing directory '%s'...%s\n", ColorRed, dirName, ColorReset)
-		err := os.RemoveAll(dirName)
-		if err != nil {
-			fmt.Printf("%s❌ Failed to delete directory: %v%s\n", ColorRed, err, ColorReset)
-			return false
-		}
-		fmt.Printf("%s✅ Directory deleted successfully%s\n", ColorGreen, ColorReset)
-		return true
-	case "2":
-		return false
-	default:
-		fmt.Printf("%s❌ Invalid choice. Please choose 1 or 2.%s\n", ColorRed, ColorReset)
-		return handleExistingDirectory(dirName, reader) // Recursive call to ask again
-	}
-}
-
-// GetProjectConfig interactively collects basic project configuration from the user
-// This function prompts the user for:
-// - Project name (used for directory and package.json)
-// - Project description
-// - Programming language (JavaScript or TypeScript)
-//
-// If InitFlags are provided, they will be used instead of prompting the user
-// Returns a ProjectConfig struct with default features enabled for simplicity
-func GetProjectConfig(flags InitFlags) ProjectConfig {
-	reader := bufio.NewReader(os.Stdin)
-
-	config := ProjectConfig{
-		Port:       3000,
-		Version:    "1.0.0",
-		Language:   "ts",    // Default to TypeScript
-		AppAlias:   "XyP",
-		Author:     "Nehonix-Team",
-		WithAuth:   true,    // Enable by default for better DX
-		WithUpload: true,    // Enable by default for better DX
-		WithMulti:  false,   // Keep simple by default
-	}
-
-	// Project name - used for directory name and package.json
-	if flags.Name != "" {
-		config.Name = flags.Name
-	} else {
-		fmt.Printf("%sProject name:%s ", ColorCyan, ColorReset)
-		name, _ := reader.ReadString('\n')
-		config.Name = strings.TrimSpace(name)
-		if config.Name == "" {
-			config.Name = "my-xypriss-app"
+// requireValue rejects empty answers
+func requireValue(field string) func(string) (string, error) {
+	return func(value string) (string, error) {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return "", fmt.Errorf("the %s cannot be empty", field)
 		}
+		return value, nil
 	}
+}
 
-	// Check if directory exists and handle it
-	if !handleExistingDirectory(config.Name, reader) {
-		// User chose to use a different name
-		return GetProjectConfig(InitFlags{}) // Reset flags for new interactive session
+// directoryInUse reports whether a directory exists and is not empty
+func directoryInUse(dirName string) bool {
+	files, err := ioutil.ReadDir(dirName)
+	return err == nil && len(files) > 0
+}
+
+// projectWizard asks the init questions that were not answered by flags or settings
+type projectWizard struct {
+	prompter *Prompter
+	flags    InitFlags
+	config   ProjectConfig
+	asked    bool // At least one question was asked
+}
+
+// GetProjectConfig collects the project configuration with an interactive wizard
+// Values given as flags (or configured defaults) are validated and not asked again.
+// On a terminal the wizard also asks for the package manager, features and license,
+// then shows a review screen where any answer can be edited before continuing
+func (c *CLITool) GetProjectConfig(flags InitFlags) (ProjectConfig, error) {
+	w := &projectWizard{
+		prompter: NewPrompter(),
+		flags:    flags,
+		config: ProjectConfig{
+			Port:       3000,
+			Version:    "1.0.0",
+			Language:   "ts", // Default to TypeScript
+			AppAlias:   "XyP",
+			Author:     "Nehonix-Team",
+			WithAuth:   true,  // Enable by default for better DX
+			WithUpload: true,  // Enable by default for better DX
+			WithMulti:  false, // Keep simple by default
+			Mode:       flags.Mode,
+			Env:        orDefault(flags.Env, DefaultEnvironment),
+		},
 	}
 
-	// Project description - used in package.json and README
-	if flags.Description != "" {
-		config.Description = flags.Description
-	} else {
-		fmt.Printf("%sDescription:%s ", ColorCyan, ColorReset)
-		desc, _ := reader.ReadString('\n')
-		config.Description = strings.TrimSpace(desc)
-		if config.Description == "" {
-			config.Description = "A XyPriss application"
+	// Validate every given value before asking anything
+	for _, field := range projectFields {
+		if value := w.given(field); value != "" {
+			if err := w.apply(field, value); err != nil {
+				return w.config, err
+			}
+		}
+	}
+	for _, field := range projectFields {
+		if w.given(field) != "" {
+			continue
+		}
+		// Questions added with the wizard are only asked to people, so piped answers keep working
+		if (field == "mode" || field == "features" || field == "license") && !w.prompter.Interactive() {
+			continue
+		}
+		w.asked = true
+		if err := w.ask(field); err != nil {
+			return w.config, err
 		}
 	}
 
-	// Programming language selection
-	if flags.Language != "" {
-		config.Language = strings.ToLower(flags.Language)
-		if config.Language != "js" && config.Language != "ts" {
-			config.Language = "ts" // Default to TypeScript
+	if !w.asked || !w.prompter.Interactive() {
+		c.displayProjectConfig(w.config)
+		warnPortConflicts(w.config.Name, w.config.Port)
+		return w.config, nil
+	}
+	return w.config, w.review(c)
+}
+
+// projectFields lists the wizard questions in order
+var projectFields = []string{"name", "description", "lang", "port", "version", "alias", "author", "mode", "features", "license"}
+
+// given returns the value of a field passed as a flag (or configured), if any
+func (w *projectWizard) given(field string) string {
+	return map[string]string{
+		"name":        w.flags.Name,
+		"description": w.flags.Description,
+		"lang":        w.flags.Language,
+		"port":        w.flags.Port,
+		"version":     w.flags.Version,
+		"alias":       w.flags.Alias,
+		"author":      w.flags.Author,
+		"mode":        w.flags.Mode,
+		"license":     w.flags.License,
+	}[field]
+}
+
+// apply validates and stores a value given as a flag
+func (w *projectWizard) apply(field, value string) error {
+	validators := map[string]func(string) (string, error){
+		"name":    validateProjectName,
+		"lang":    validateLanguage,
+		"port":    validatePort,
+		"version": validateVersion,
+	}
+	if validate, ok := validators[field]; ok {
+		normalized, err := validate(value)
+		if err != nil {
+			return usageError("invalid %s: %v", field, err)
 		}
-	} else {
-		fmt.Printf("%sProgramming language (js/ts):%s ", ColorCyan, ColorReset)
-		lang, _ := reader.ReadString('\n')
-		config.Language = strings.TrimSpace(strings.ToLower(lang))
-		if config.Language != "js" && config.Language != "ts" {
-			config.Language = "ts" // Default to TypeScript
+		value = normalized
+	}
+
+	switch field {
+	case "name":
+		w.config.Name = value
+		if directoryInUse(value) {
+			return w.handleExistingDirectory()
 		}
+	case "description":
+		w.config.Description = value
+	case "lang":
+		w.config.Language = value
+	case "port":
+		w.config.Port, _ = strconv.Atoi(value)
+	case "version":
+		w.config.Version = value
+	case "alias":
+		w.config.AppAlias = value
+	case "author":
+		w.config.Author = value
+	case "mode":
+		w.config.Mode = value
+	case "license":
+		w.config.License = value
 	}
+	return nil
+}
 
-	// Server port selection
-	if flags.Port != "" {
-		if port, err := strconv.Atoi(flags.Port); err == nil && port > 0 && port < 65536 {
-			config.Port = port
+// ask asks one question and stores the answer
+func (w *projectWizard) ask(field string) error {
+	p := w.prompter
+	var (
+		value string
+		err   error
+	)
+
+	switch field {
+	case "name":
+		value, err = p.Input("Project name", orDefault(w.config.Name, "my-xypriss-app"), validateProjectName)
+	case "description":
+		value, err = p.Input("Description", orDefault(w.config.Description, "A XyPriss application"), nil)
+	case "lang":
+		var index int
+		if p.raw {
+			index, err = p.Select("Language", languageOptions, optionIndex(languageOptions, w.config.Language))
+			value = languageOptions[index].Value
 		} else {
-			fmt.Printf("%sInvalid port format, using default 3000%s\n", ColorYellow, ColorReset)
+			value, err = p.Input("Programming language (js/ts)", w.config.Language, validateLanguage)
 		}
-	} else {
-		fmt.Printf("%sServer port:%s ", ColorCyan, ColorReset)
-		portStr, _ := reader.ReadString('\n')
-		portStr = strings.TrimSpace(portStr)
-		if portStr != "" {
-			if port, err := strconv.Atoi(portStr); err == nil && port > 0 && port < 65536 {
-				config.Port = port
-			} else {
-				fmt.Printf("%sInvalid port format, using default 3000%s\n", ColorYellow, ColorReset)
-			}
+	case "port":
+		value, err = p.Input("Server port", strconv.Itoa(w.config.Port), validatePort)
+	case "version":
+		value, err = p.Input("Application version", w.config.Version, validateVersion)
+	case "alias":
+		value, err = p.Input("Application alias", w.config.AppAlias, requireValue("alias"))
+	case "author":
+		value, err = p.Input("Author name", w.config.Author, requireValue("author"))
+	case "mode":
+		var index int
+		index, err = p.Select("Package manager", packageManagerOptions, optionIndex(packageManagerOptions, w.config.Mode))
+		value = packageManagerOptions[index].Value
+		if err == nil {
+			w.config.Mode = value
 		}
+		return err
+	case "features":
+		var checked []bool
+		checked, err = p.MultiSelect("Features", featureOptions, []bool{w.config.WithAuth, w.config.WithUpload, w.config.WithMulti})
+		if err == nil {
+			w.config.WithAuth, w.config.WithUpload, w.config.WithMulti = checked[0], checked[1], checked[2]
+		}
+		return err
+	case "license":
+		var index int
+		index, err = p.Select("License", licenseOptions, optionIndex(licenseOptions, w.config.License))
+		value = licenseOptions[index].Value
+	}
+	if err != nil {
+		return err
 	}
+	return w.apply(field, value)
+}
 
-	// Application version
-	if flags.Version != "" {
-		config.Version = flags.Version
-	} else {
-		fmt.Printf("%sApplication version:%s ", ColorCyan, ColorReset)
-		version, _ := reader.ReadString('\n')
-		config.Version = strings.TrimSpace(version)
-		if config.Version == "" {
-			config.Version = "1.0.0"
-		}
+// handleExistingDirectory asks what to do when the project directory is not empty
+func (w *projectWizard) handleExistingDirectory() error {
+	dirName := w.config.Name
+	printf("\n%s⚠ Directory '%s' already exists and is not empty.%s\n", ColorYellow, dirName, ColorReset)
+	choice, err := w.prompter.Select("What would you like to do?", []Option{
+		{Label: "Delete the directory and create a new project", Value: "delete"},
+		{Label: "Choose a different project name", Value: "rename"},
+	}, 1)
+	if err != nil {
+		return err
 	}
 
-	// Application alias
-	if flags.Alias != "" {
-		config.AppAlias = flags.Alias
-	} else {
-		fmt.Printf("%sApplication alias:%s ", ColorCyan, ColorReset)
-		appAlias, _ := reader.ReadString('\n')
-		config.AppAlias = strings.TrimSpace(appAlias)
-		if config.AppAlias == "" {
-			config.AppAlias = "XyP"
+	if choice == 0 {
+		printf("%s🗑️  Deleting existing directory '%s'...%s\n", ColorRed, dirName, ColorReset)
+		if err := os.RemoveAll(dirName); err != nil {
+			return failureError(err, "failed to delete directory %s", dirName)
 		}
+		printf("%s✅ Directory deleted successfully%s\n", ColorGreen, ColorReset)
+		return nil
+	}
+
+	if !w.prompter.Interactive() && w.flags.Name != "" {
+		return usageError("directory '%s' already exists and is not empty", dirName).
+			WithHint("Choose another name with --name")
 	}
+	w.config.Name = ""
+	w.asked = true
+	return w.ask("name")
+}
+
+// review shows the configuration and lets the user edit any answer before continuing
+func (w *projectWizard) review(c *CLITool) error {
+	for {
+		printLine()
+		c.displayProjectConfig(w.config)
+		warnPortConflicts(w.config.Name, w.config.Port)
+		printLine()
+
+		options := []Option{{Label: "Looks good, create the project", Value: "continue"}}
+		for _, field := range projectFields {
+			options = append(options, Option{Label: "Edit " + fieldLabel(field), Value: field})
+		}
+		options = append(options, Option{Label: "Cancel", Value: "cancel"})
 
-	// Author name
-	if flags.Author != "" {
-		config.Author = flags.Author
-	} else {
-		fmt.Printf("%sAuthor name:%s ", ColorCyan, ColorReset)
-		author, _ := reader.ReadString('\n')
-		config.Author = strings.TrimSpace(author)
-		if config.Author == "" {
-			config.Author = "Nehonix"
+		choice, err := w.prompter.Select("Review", options, 0)
+		if err != nil {
+			return err
+		}
+		switch options[choice].Value {
+		case "continue":
+			return nil
+		case "cancel":
+			return interruptedError()
+		default:
+			if err := w.ask(options[choice].Value); err != nil {
+				return err
+			}
 		}
 	}
+}
+
+// fieldLabel returns the human-readable name of a wizard field
+func fieldLabel(field string) string {
+	return map[string]string{
+		"name":        "name",
+		"description": "description",
+		"lang":        "language",
+		"port":        "port",
+		"version":     "version",
+		"alias":       "alias",
+		"author":      "author",
+		"mode":        "package manager",
+		"features":    "features",
+		"license":     "license",
+	}[field]
+}
 
-	return config
-}
\ No newline at end of file
+// orDefault returns value, or fallback when value is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// packageManagerName returns the display name of an installation mode
+func packageManagerName(mode string) string {
+	return packageManagerOptions[optionIndex(packageManagerOptions, mode)].Label
+}
@@ -0,0 +1,285 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Documentation formats supported by "xypcli docs"
+const (
+	DocsMan      = "man"
+	DocsMarkdown = "md"
+)
+
+// GenerateDocs writes the reference documentation of every command to outDir
+// With check, nothing is written: the files in outDir are compared with the
+// generated ones and any difference is reported as an error
+func (c *CLITool) GenerateDocs(format, outDir string, check bool) error {
+	pages := renderDocs(c.commandTree(), format)
+
+	if check {
+		return checkDocs(pages, format, outDir)
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return failureError(err, "failed to create %s", outDir)
+	}
+	for _, name := range sortedPageNames(pages) {
+		path := filepath.Join(outDir, name)
+		if err := os.WriteFile(path, []byte(pages[name]), 0644); err != nil {
+			return failureError(err, "failed to write %s", path)
+		}
+	}
+	printf("%s✓ Generated %d %s page(s) in %s%s\n", ColorGreen, len(pages), format, outDir, ColorReset)
+	return nil
+}
+
+// checkDocs compares generated pages with the files on disk
+func checkDocs(pages map[string]string, format, outDir string) error {
+	problems := []string{}
+	for _, name := range sortedPageNames(pages) {
+		existing, err := os.ReadFile(filepath.Join(outDir, name))
+		switch {
+		case err != nil:
+			problems = append(problems, name+" is missing")
+		case string(existing) != pages[name]:
+			problems = append(problems, name+" is out of date")
+		}
+	}
+
+	extension := "." + docsExtension(format)
+	entries, _ := os.ReadDir(outDir)
+	for _, entry := range entries {
+		if _, ok := pages[entry.Name()]; !ok && strings.HasSuffix(entry.Name(), extension) {
+			problems = append(problems, entry.Name()+" documents a command that no longer exists")
+		}
+	}
+
+	if len(problems) == 0 {
+		printf("%s✓ %s docs in %s match the command definitions%s\n", ColorGreen, format, outDir, ColorReset)
+		return nil
+	}
+	for _, problem := range problems {
+		printf("  %s✗ %s%s\n", ColorRed, problem, ColorReset)
+	}
+	return failureError(nil, "%s docs in %s disagree with the command definitions", format, outDir).
+		WithHint("Run 'xypcli docs --format %s --out %s' to regenerate them", format, outDir)
+}
+
+// renderDocs renders one page per visible command (plus the top-level page)
+func renderDocs(root *Command, format string) map[string]string {
+	pages := map[string]string{}
+	commands := append([]*Command{root}, allCommands(root)...)
+	for _, cmd := range commands {
+		name := docsPageName(cmd) + "." + docsExtension(format)
+		if format == DocsMan {
+			pages[name] = renderManPage(cmd)
+		} else {
+			pages[name] = renderMarkdownPage(cmd)
+		}
+	}
+	return pages
+}
+
+// docsExtension returns the file extension of a documentation format
+func docsExtension(format string) string {
+	if format == DocsMan {
+		return "1"
+	}
+	return "md"
+}
+
+// docsPageName returns the page name of a command (e.g. "xypcli-completion-install")
+func docsPageName(cmd *Command) string {
+	return strings.ReplaceAll(cmd.FullName(), " ", "-")
+}
+
+// sortedPageNames returns the page names in a stable order
+func sortedPageNames(pages map[string]string) []string {
+	names := []string{}
+	for name := range pages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// commandDescription returns the long description of a command, or its summary
+func commandDescription(cmd *Command) string {
+	if cmd.Description != "" {
+		return cmd.Description
+	}
+	return cmd.Summary
+}
+
+// renderMarkdownPage renders the Markdown reference page of a command
+func renderMarkdownPage(cmd *Command) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", cmd.FullName())
+	fmt.Fprintf(&b, "%s\n\n", strings.ReplaceAll(commandDescription(cmd), "\n", "\n\n"))
+
+	fmt.Fprintf(&b, "## Usage\n\n```\n%s\n```\n\n", commandSynopsis(cmd))
+
+	if len(cmd.Aliases) > 0 {
+		fmt.Fprintf(&b, "Aliases: `%s`\n\n", strings.Join(cmd.Aliases, "`, `"))
+	}
+
+	if subs := cmd.VisibleSubcommands(); len(subs) > 0 {
+		b.WriteString("## Commands\n\n| Command | Description |\n| ------- | ----------- |\n")
+		for _, sub := range subs {
+			fmt.Fprintf(&b, "| [`%s`](%s.md) | %s |\n", sub.Name, docsPageName(sub), markdownCell(sub.Summary))
+		}
+		b.WriteString("\n")
+	}
+
+	writeMarkdownFlags(&b, "Options", cmd.Flags)
+	if cmd.parent == nil {
+		writeMarkdownFlags(&b, "Global Options", globalFlags)
+	} else {
+		names := []string{}
+		for _, flag := range globalFlags {
+			names = append(names, "`--"+flag.Name+"`")
+		}
+		fmt.Fprintf(&b, "Global options (%s) are described in [xypcli](xypcli.md#global-options).\n\n", strings.Join(names, ", "))
+	}
+
+	if len(cmd.Examples) > 0 {
+		b.WriteString("## Examples\n\n```bash\n")
+		for _, row := range alignedRows(exampleRows(cmd.Examples)) {
+			b.WriteString(row + "\n")
+		}
+		b.WriteString("```\n\n")
+	}
+
+	if cmd.parent != nil {
+		fmt.Fprintf(&b, "## See Also\n\n- [%s](%s.md)\n", cmd.parent.FullName(), docsPageName(cmd.parent))
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// writeMarkdownFlags renders a table of flags
+func writeMarkdownFlags(b *strings.Builder, title string, flags []FlagDef) {
+	if len(flags) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "## %s\n\n| Flag | Description |\n| ---- | ----------- |\n", title)
+	for _, flag := range flags {
+		fmt.Fprintf(b, "| `%s` | %s |\n", markdownCell(flagSynopsis(flag)), markdownCell(flagDescription(flag)))
+	}
+	b.WriteString("\n")
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
+
+// alignedRows renders help rows with aligned descriptions, as printed by help
+func alignedRows(rows []helpRow) []string {
+	width := 0
+	for _, row := range rows {
+		if len(row.name) > width {
+			width = len(row.name)
+		}
+	}
+	lines := []string{}
+	for _, row := range rows {
+		lines = append(lines, row.name+strings.Repeat(" ", width-len(row.name))+"  "+row.description)
+	}
+	return lines
+}
+
+// renderManPage renders the troff man page of a command
+func renderManPage(cmd *Command) string {
+	var b strings.Builder
+	title := strings.ToUpper(docsPageName(cmd))
+	fmt.Fprintf(&b, ".TH %s 1 \"\" \"XyPCLI\" \"XyPCLI Manual\"\n", manEscape(title))
+
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- %s\n", manEscape(docsPageName(cmd)), manEscape(cmd.Summary))
+
+	b.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&b, ".B %s\n", manArgument(commandSynopsis(cmd)))
+
+	b.WriteString(".SH DESCRIPTION\n")
+	for i, line := range strings.Split(commandDescription(cmd), "\n") {
+		if i > 0 {
+			b.WriteString(".PP\n")
+		}
+		b.WriteString(manLine(line) + "\n")
+	}
+	if len(cmd.Aliases) > 0 {
+		fmt.Fprintf(&b, ".PP\nAliases: %s\n", manEscape(strings.Join(cmd.Aliases, ", ")))
+	}
+
+	if subs := cmd.VisibleSubcommands(); len(subs) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, sub := range subs {
+			fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", manArgument(sub.Name), manLine(sub.Summary))
+		}
+	}
+
+	writeManFlags(&b, "OPTIONS", cmd.Flags)
+	writeManFlags(&b, "GLOBAL OPTIONS", globalFlags)
+
+	if len(cmd.Examples) > 0 {
+		b.WriteString(".SH EXAMPLES\n")
+		for _, example := range cmd.Examples {
+			fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", manArgument(example.Command), manLine(example.Comment))
+		}
+	}
+
+	b.WriteString(".SH EXIT STATUS\n")
+	b.WriteString("0 on success, 1 on failure, 2 on usage errors, 3 on environment errors, 4 on network errors, 5 on install failures, 6 on template errors, 130 when interrupted.\n")
+
+	b.WriteString(".SH SEE ALSO\n")
+	related := []string{}
+	if cmd.parent != nil {
+		related = append(related, docsPageName(cmd.parent))
+	}
+	for _, sub := range cmd.VisibleSubcommands() {
+		related = append(related, docsPageName(sub))
+	}
+	for i, name := range related {
+		separator := ","
+		if i == len(related)-1 {
+			separator = ""
+		}
+		fmt.Fprintf(&b, ".BR %s (1)%s\n", manEscape(name), separator)
+	}
+	return b.String()
+}
+
+// writeManFlags renders a section of flags
+func writeManFlags(b *strings.Builder, title string, flags []FlagDef) {
+	if len(flags) == 0 {
+		return
+	}
+	fmt.Fprintf(b, ".SH %s\n", title)
+	for _, flag := range flags {
+		fmt.Fprintf(b, ".TP\n.B %s\n%s\n", manArgument(flagSynopsis(flag)), manLine(flagDescription(flag)))
+	}
+}
+
+// manEscape escapes text for troff
+func manEscape(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\e`)
+	return strings.ReplaceAll(text, "-", `\-`)
+}
+
+// manArgument quotes text as a single macro argument
+func manArgument(text string) string {
+	return `"` + strings.ReplaceAll(manEscape(text), `"`, `\(dq`) + `"`
+}
+
+// manLine escapes a text line, protecting lines that troff would read as requests
+func manLine(text string) string {
+	text = manEscape(text)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		return `\&` + text
+	}
+	return text
+}
@@ -0,0 +1,105 @@
+package modules
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update rewrites the golden files instead of comparing against them:
+//
+//	go test ./modules -update
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// captureStdout returns what fn writes to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	stdout := os.Stdout
+	os.Stdout = file
+	defer func() { os.Stdout = stdout }()
+	fn()
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// checkGolden compares got with the golden file at path, or rewrites it with -update
+func checkGolden(t *testing.T, path, got string) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run 'go test ./modules -update' to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s is out of date (run 'go test ./modules -update' after checking the diff)\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+// TestCommandHelp checks the help of every registered command against testdata/help
+func TestCommandHelp(t *testing.T) {
+	disableColors()
+	root := NewCLITool("test").commandTree()
+	commands := append([]*Command{root}, allCommands(root)...)
+
+	pages := map[string]bool{}
+	for _, cmd := range commands {
+		name := docsPageName(cmd) + ".txt"
+		pages[name] = true
+		t.Run(cmd.FullName(), func(t *testing.T) {
+			got := captureStdout(t, func() { printCommandHelp(cmd, globalFlags) })
+			checkGolden(t, filepath.Join("testdata", "help", name), got)
+		})
+	}
+
+	entries, _ := os.ReadDir(filepath.Join("testdata", "help"))
+	for _, entry := range entries {
+		if !pages[entry.Name()] {
+			if *update {
+				os.Remove(filepath.Join("testdata", "help", entry.Name()))
+				continue
+			}
+			t.Errorf("testdata/help/%s documents a command that no longer exists", entry.Name())
+		}
+	}
+}
+
+// TestRenderDocs checks renderDocs against the reference docs committed in docs/
+func TestRenderDocs(t *testing.T) {
+	root := NewCLITool("test").commandTree()
+	for _, format := range []string{DocsMarkdown, DocsMan} {
+		dir := filepath.Join("..", "docs", format)
+		pages := renderDocs(root, format)
+		for _, name := range sortedPageNames(pages) {
+			t.Run(format+"/"+name, func(t *testing.T) {
+				checkGolden(t, filepath.Join(dir, name), pages[name])
+			})
+		}
+
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			if _, ok := pages[entry.Name()]; !ok && strings.HasSuffix(entry.Name(), "."+docsExtension(format)) {
+				t.Errorf("docs/%s/%s documents a command that no longer exists", format, entry.Name())
+			}
+		}
+	}
+}
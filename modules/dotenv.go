@@ -0,0 +1,316 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// EnvExampleFile lists the variables of a project, without their values
+const EnvExampleFile = ".env.example"
+
+// dotenvKeyPattern matches valid variable names
+var dotenvKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// dotenvEntry is one line of a dotenv file, or one assignment spanning several lines
+type dotenvEntry struct {
+	Raw     string // Text as found in the file, kept as is unless the entry is changed
+	Key     string // Empty for comments and blank lines
+	Value   string
+	Export  bool   // Written with an "export " prefix
+	Comment string // Inline comment after the value, including its "#"
+}
+
+// dotenvFile is a dotenv file that can be edited without losing comments, blank lines or order
+type dotenvFile struct {
+	path    string
+	exists  bool
+	entries []dotenvEntry
+}
+
+// parseDotenvEntries splits dotenv content into entries
+// Supports comments, an "export " prefix, single quotes (literal), double quotes
+// (with \n, \t, \" and \\ escapes, possibly spanning several lines) and inline
+// comments after unquoted values
+func parseDotenvEntries(content string) []dotenvEntry {
+	entries := []dotenvEntry{}
+	content = strings.TrimSuffix(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	if content == "" {
+		return entries
+	}
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		entry := dotenvEntry{Raw: lines[i]}
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			entries = append(entries, entry)
+			continue
+		}
+		if strings.HasPrefix(line, "export ") {
+			entry.Export = true
+			line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		}
+		separator := strings.Index(line, "=")
+		if separator <= 0 {
+			entries = append(entries, dotenvEntry{Raw: lines[i]})
+			continue
+		}
+		entry.Key = strings.TrimSpace(line[:separator])
+		raw := strings.TrimSpace(line[separator+1:])
+
+		// A quoted value may continue on the next lines until its closing quote
+		if quote := quoteOf(raw); quote != 0 {
+			for closingQuote(raw[1:], quote) < 0 && i+1 < len(lines) {
+				i++
+				raw += "\n" + lines[i]
+				entry.Raw += "\n" + lines[i]
+			}
+		}
+		entry.Value = unquoteDotenv(raw)
+		entry.Comment = inlineComment(raw)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// parseDotenv returns the variables of dotenv content; the last assignment of a key wins
+func parseDotenv(content string) map[string]string {
+	values := map[string]string{}
+	for _, entry := range parseDotenvEntries(content) {
+		if entry.Key != "" {
+			values[entry.Key] = entry.Value
+		}
+	}
+	return values
+}
+
+// quoteOf returns the quote opening a raw value, or 0
+func quoteOf(raw string) byte {
+	if raw != "" && (raw[0] == '"' || raw[0] == '\'' || raw[0] == '`') {
+		return raw[0]
+	}
+	return 0
+}
+
+// closingQuote returns the index of the unescaped quote ending s, or -1
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && quote == '"' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// unquoteDotenv returns the value of a raw dotenv value
+func unquoteDotenv(raw string) string {
+	quote := quoteOf(raw)
+	if quote == 0 {
+		// Unquoted: the value ends where its inline comment starts
+		if index := commentStart(raw); index >= 0 {
+			raw = raw[:index]
+		}
+		return strings.TrimSpace(raw)
+	}
+	end := closingQuote(raw[1:], quote)
+	if end < 0 {
+		return raw[1:]
+	}
+	value := raw[1 : end+1]
+	if quote == '"' {
+		value = strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(value)
+	}
+	return value
+}
+
+// commentStart returns the index of the "#" starting an inline comment, or -1
+// A "#" starts a comment at the beginning of s or after a space or a tab (so "a#b" is a value)
+func commentStart(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// inlineComment returns the comment following a raw value, or ""
+func inlineComment(raw string) string {
+	rest := raw
+	if quote := quoteOf(raw); quote != 0 {
+		end := closingQuote(raw[1:], quote)
+		if end < 0 {
+			return ""
+		}
+		rest = raw[end+2:]
+	}
+	if index := commentStart(rest); index >= 0 {
+		return rest[index:]
+	}
+	return ""
+}
+
+// formatDotenvValue quotes a value when it would not survive unquoted
+func formatDotenvValue(value string) string {
+	if value == "" || !strings.ContainsAny(value, " \t\n\r#\"'`\\=$") {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\n\r") {
+		return "'" + value + "'"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`).Replace(value) + `"`
+}
+
+// validateEnvKey checks a variable name
+func validateEnvKey(key string) error {
+	if !dotenvKeyPattern.MatchString(key) {
+		return usageError("invalid variable name '%s'", key).
+			WithHint("Use letters, digits and underscores, starting with a letter (e.g. DATABASE_URL)")
+	}
+	return nil
+}
+
+// readDotenvFile reads a dotenv file; a missing file gives an empty document
+// Encrypted files are refused: they are read and changed with "xypcli secrets"
+func readDotenvFile(path string) (*dotenvFile, error) {
+	if strings.HasSuffix(path, ".enc") {
+		return nil, usageError("%s is encrypted", path).
+			WithHint("Use 'xypcli secrets get/set/edit' for encrypted files")
+	}
+	file := &dotenvFile{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, environmentError("failed to read %s: %v", path, err)
+	}
+	file.exists = true
+	file.entries = parseDotenvEntries(string(data))
+	return file, nil
+}
+
+// keys returns the variables of the file in order, without duplicates
+func (f *dotenvFile) keys() []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, entry := range f.entries {
+		if entry.Key != "" && !seen[entry.Key] {
+			seen[entry.Key] = true
+			keys = append(keys, entry.Key)
+		}
+	}
+	return keys
+}
+
+// values returns the variables of the file; the last assignment of a key wins
+func (f *dotenvFile) values() map[string]string {
+	values := map[string]string{}
+	for _, entry := range f.entries {
+		if entry.Key != "" {
+			values[entry.Key] = entry.Value
+		}
+	}
+	return values
+}
+
+// lookup returns the value of key
+func (f *dotenvFile) lookup(key string) (string, bool) {
+	value, ok := f.values()[key]
+	return value, ok
+}
+
+// set assigns key, in place when it exists (keeping its export prefix) or at the end
+// Returns true when the key was added
+func (f *dotenvFile) set(key, value string) bool {
+	for i := len(f.entries) - 1; i >= 0; i-- {
+		entry := &f.entries[i]
+		if entry.Key != key {
+			continue
+		}
+		if entry.Value == value {
+			return false
+		}
+		entry.Value = value
+		entry.Raw = key + "=" + formatDotenvValue(value)
+		if entry.Export {
+			entry.Raw = "export " + entry.Raw
+		}
+		if entry.Comment != "" {
+			entry.Raw += " " + entry.Comment
+		}
+		return false
+	}
+	f.entries = append(f.entries, dotenvEntry{Raw: key + "=" + formatDotenvValue(value), Key: key, Value: value})
+	return true
+}
+
+// unset removes every assignment of key; returns false when it was not set
+func (f *dotenvFile) unset(key string) bool {
+	kept := f.entries[:0]
+	removed := false
+	for _, entry := range f.entries {
+		if entry.Key == key {
+			removed = true
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	f.entries = kept
+	return removed
+}
+
+// content returns the text of the file
+func (f *dotenvFile) content() string {
+	lines := make([]string, 0, len(f.entries))
+	for _, entry := range f.entries {
+		lines = append(lines, entry.Raw)
+	}
+	content := strings.Join(lines, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	return content
+}
+
+// write saves the file; new files other than the example are created readable by their owner only
+func (f *dotenvFile) write() error {
+	mode := os.FileMode(0600)
+	if filepath.Base(f.path) == EnvExampleFile {
+		mode = 0644
+	}
+	if info, err := os.Stat(f.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(f.path, []byte(f.content()), mode); err != nil {
+		return failureError(err, "failed to write %s", f.path)
+	}
+	f.exists = true
+	return nil
+}
+
+// requiredKeys returns the keys of an example file that must be set
+// Every key is required unless the comment line right above it contains "optional"
+func (f *dotenvFile) requiredKeys() []string {
+	required := []string{}
+	seen := map[string]bool{}
+	for i, entry := range f.entries {
+		if entry.Key == "" || seen[entry.Key] {
+			continue
+		}
+		seen[entry.Key] = true
+		if i > 0 {
+			previous := strings.TrimSpace(f.entries[i-1].Raw)
+			if strings.HasPrefix(previous, "#") && strings.Contains(strings.ToLower(previous), "optional") {
+				continue
+			}
+		}
+		required = append(required, entry.Key)
+	}
+	return required
+}
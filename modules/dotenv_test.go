@@ -0,0 +1,137 @@
+package modules
+
+import "testing"
+
+func TestParseDotenvEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		value   string
+		comment string
+		export  bool
+	}{
+		{"plain", "A=value", "A", "value", "", false},
+		{"empty", "A=", "A", "", "", false},
+		{"spaces around", "  A = value  ", "A", "value", "", false},
+		{"export", "export A=value", "A", "value", "", true},
+		{"single quotes are literal", `A='a\nb $HOME'`, "A", `a\nb $HOME`, "", false},
+		{"double quote escapes", `A="a\nb\t\"c\" \\d"`, "A", "a\nb\t\"c\" \\d", "", false},
+		{"hash inside quotes", `A="a # b"`, "A", "a # b", "", false},
+		{"hash inside a word", "A=a#b", "A", "a#b", "", false},
+		{"comment after space", "A=value # note", "A", "value", "# note", false},
+		{"comment after tab", "A=value\t# note", "A", "value", "# note", false},
+		{"comment only", "A= # note", "A", "", "# note", false},
+		{"comment after quotes", `A="value" # note`, "A", "value", "# note", false},
+		{"comment after single quotes with tab", "A='value'\t# note", "A", "value", "# note", false},
+		{"multiline double quotes", "A=\"line 1\nline 2\"", "A", "line 1\nline 2", "", false},
+		{"multiline single quotes", "A='line 1\nline 2' # note", "A", "line 1\nline 2", "# note", false},
+		{"unterminated quote", `A="value`, "A", "value", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries := parseDotenvEntries(test.content)
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1: %#v", len(entries), entries)
+			}
+			entry := entries[0]
+			if entry.Key != test.key || entry.Value != test.value || entry.Comment != test.comment || entry.Export != test.export {
+				t.Errorf("got key %q value %q comment %q export %v, want %q %q %q %v",
+					entry.Key, entry.Value, entry.Comment, entry.Export, test.key, test.value, test.comment, test.export)
+			}
+			if entry.Raw != test.content {
+				t.Errorf("raw text %q, want %q", entry.Raw, test.content)
+			}
+		})
+	}
+}
+
+func TestParseDotenvEntriesKeepsOtherLines(t *testing.T) {
+	content := "# Database\n\nexport DB_URL=postgres://x\nnot an assignment\nMULTI=\"a\nb\"\nLAST=1\n"
+	entries := parseDotenvEntries(content)
+	keys := []string{}
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	want := []string{"", "", "DB_URL", "", "MULTI", "LAST"}
+	if len(keys) != len(want) {
+		t.Fatalf("got keys %q, want %q", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("got keys %q, want %q", keys, want)
+		}
+	}
+	if got := (&dotenvFile{entries: entries}).content(); got != content {
+		t.Errorf("content changed:\n%q\nwant\n%q", got, content)
+	}
+}
+
+func TestDotenvSetUnset(t *testing.T) {
+	const content = "# Server\n" +
+		"PORT=3000 # dev port\n" +
+		"\n" +
+		"export TOKEN='abc'\n" +
+		"NOTE=\"multi\n" +
+		"line\"\n" +
+		"DEBUG=1\t# tab comment\n" +
+		"OLD=x\n"
+
+	tests := []struct {
+		name   string
+		change func(f *dotenvFile)
+		want   string
+	}{
+		{
+			name:   "set in place keeps the comment",
+			change: func(f *dotenvFile) { f.set("PORT", "4000") },
+			want:   "# Server\nPORT=4000 # dev port\n\nexport TOKEN='abc'\nNOTE=\"multi\nline\"\nDEBUG=1\t# tab comment\nOLD=x\n",
+		},
+		{
+			name:   "set keeps the export prefix and quotes when needed",
+			change: func(f *dotenvFile) { f.set("TOKEN", "a b") },
+			want:   "# Server\nPORT=3000 # dev port\n\nexport TOKEN='a b'\nNOTE=\"multi\nline\"\nDEBUG=1\t# tab comment\nOLD=x\n",
+		},
+		{
+			name:   "set after a tab comment writes the comment once",
+			change: func(f *dotenvFile) { f.set("DEBUG", "0") },
+			want:   "# Server\nPORT=3000 # dev port\n\nexport TOKEN='abc'\nNOTE=\"multi\nline\"\nDEBUG=0 # tab comment\nOLD=x\n",
+		},
+		{
+			name:   "set the same value changes nothing",
+			change: func(f *dotenvFile) { f.set("DEBUG", "1") },
+			want:   content,
+		},
+		{
+			name:   "set a new key appends it",
+			change: func(f *dotenvFile) { f.set("NEW", "line 1\nline 2") },
+			want:   content + "NEW=\"line 1\\nline 2\"\n",
+		},
+		{
+			name:   "unset a multiline value",
+			change: func(f *dotenvFile) { f.unset("NOTE") },
+			want:   "# Server\nPORT=3000 # dev port\n\nexport TOKEN='abc'\nDEBUG=1\t# tab comment\nOLD=x\n",
+		},
+		{
+			name:   "unset a missing key",
+			change: func(f *dotenvFile) { f.unset("MISSING") },
+			want:   content,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := &dotenvFile{entries: parseDotenvEntries(content)}
+			test.change(file)
+			if got := file.content(); got != test.want {
+				t.Errorf("got\n%q\nwant\n%q", got, test.want)
+			}
+			// The result must parse back to the same values
+			reparsed := (&dotenvFile{entries: parseDotenvEntries(file.content())}).values()
+			for key, value := range file.values() {
+				if reparsed[key] != value {
+					t.Errorf("%s reparsed as %q, want %q", key, reparsed[key], value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Exit codes returned by xypcli
+// They are part of the public interface: scripts rely on them, so never renumber
+const (
+	ExitOK          = 0   // Command succeeded
+	ExitFailure     = 1   // Unclassified failure
+	ExitUsage       = 2   // Invalid command, flag or argument
+	ExitEnvironment = 3   // Missing project files or tools (package.json, npm, ...)
+	ExitNetwork     = 4   // Network or registry unreachable
+	ExitInstall     = 5   // One or more packages failed to install
+	ExitTemplate    = 6   // Template could not be downloaded or extracted
+	ExitInterrupted = 130 // Interrupted by SIGINT/SIGTERM
+)
+
+// ErrorKind classifies a CLIError; each kind maps to one exit code
+type ErrorKind string
+
+const (
+	KindFailure     ErrorKind = "failure"
+	KindUsage       ErrorKind = "usage"
+	KindEnvironment ErrorKind = "environment"
+	KindNetwork     ErrorKind = "network"
+	KindInstall     ErrorKind = "install"
+	KindTemplate    ErrorKind = "template"
+	KindInterrupted ErrorKind = "interrupted"
+)
+
+// exitCodes maps error kinds to process exit codes
+var exitCodes = map[ErrorKind]int{
+	KindFailure:     ExitFailure,
+	KindUsage:       ExitUsage,
+	KindEnvironment: ExitEnvironment,
+	KindNetwork:     ExitNetwork,
+	KindInstall:     ExitInstall,
+	KindTemplate:    ExitTemplate,
+	KindInterrupted: ExitInterrupted,
+}
+
+// CLIError is the error type returned by commands
+// The top-level handler in Run prints it and exits with the matching code
+type CLIError struct {
+	Kind    ErrorKind
+	Message string // One line description of what went wrong
+	Hint    string // Optional suggestion printed under the message
+	Err     error  // Underlying cause, if any
+}
+
+// Error implements the error interface
+func (e *CLIError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause
+func (e *CLIError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code for the error
+func (e *CLIError) ExitCode() int {
+	if code, ok := exitCodes[e.Kind]; ok {
+		return code
+	}
+	return ExitFailure
+}
+
+// WithHint attaches a suggestion to the error
+func (e *CLIError) WithHint(format string, a ...interface{}) *CLIError {
+	e.Hint = fmt.Sprintf(format, a...)
+	return e
+}
+
+// newError creates a CLIError of the given kind
+func newError(kind ErrorKind, cause error, format string, a ...interface{}) *CLIError {
+	return &CLIError{Kind: kind, Message: fmt.Sprintf(format, a...), Err: cause}
+}
+
+// usageError reports an invalid command, flag or argument
+func usageError(format string, a ...interface{}) *CLIError {
+	return newError(KindUsage, nil, format, a...)
+}
+
+// environmentError reports missing project files or tools
+func environmentError(format string, a ...interface{}) *CLIError {
+	return newError(KindEnvironment, nil, format, a...)
+}
+
+// networkError reports an unreachable network resource
+func networkError(cause error, format string, a ...interface{}) *CLIError {
+	return newError(KindNetwork, cause, format, a...)
+}
+
+// installError reports failed package installations
+func installError(format string, a ...interface{}) *CLIError {
+	return newError(KindInstall, nil, format, a...)
+}
+
+// templateError reports a template that could not be downloaded or extracted
+func templateError(cause error, format string, a ...interface{}) *CLIError {
+	return newError(KindTemplate, cause, format, a...)
+}
+
+// failureError reports any other failure
+func failureError(cause error, format string, a ...interface{}) *CLIError {
+	return newError(KindFailure, cause, format, a...)
+}
+
+// interruptedError reports a command stopped by a signal
+func interruptedError() *CLIError {
+	return newError(KindInterrupted, nil, "interrupted")
+}
+
+// asCLIError converts any error into a CLIError (unclassified errors become failures)
+func asCLIError(err error) *CLIError {
+	var cliErr *CLIError
+	if errors.As(err, &cliErr) {
+		return cliErr
+	}
+	return failureError(nil, "%v", err)
+}
@@ -0,0 +1,215 @@
+package modules
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutputSchemaVersion is the version of the machine-readable output format
+// It is bumped whenever an event is removed or a field changes meaning;
+// adding events or fields is backwards compatible and keeps the version
+const OutputSchemaVersion = 1
+
+// Output modes selected with --output
+const (
+	OutputText   = "text"
+	OutputJSON   = "json"
+	OutputNDJSON = "ndjson"
+)
+
+// Event types emitted in json/ndjson output modes
+const (
+	EventCommandStarted          = "command.started"
+	EventTemplateDownloaded      = "template.downloaded"
+	EventTemplateExtracted       = "template.extracted"
+	EventConfigWritten           = "config.written"
+	EventPackageInstallStarted   = "package.install.started"
+	EventPackageInstallSucceeded = "package.install.succeeded"
+	EventPackageInstallFailed    = "package.install.failed"
+	EventInstallCompleted        = "install.completed"
+	EventInitCompleted           = "init.completed"
+	EventServerStarting          = "server.starting"
+	EventServerReady             = "server.ready"
+	EventServerExited            = "server.exited"
+	EventServerDetached          = "server.detached"
+	EventServerStopped           = "server.stopped"
+	EventServerStatus            = "server.status"
+	EventServiceReady            = "service.ready"
+	EventEnvResolved             = "env.resolved"
+	EventConfigValidated         = "config.validated"
+	EventCommandCompleted        = "command.completed"
+)
+
+// Event is a single machine-readable event
+type Event struct {
+	Schema int                    `json:"schema"`
+	Type   string                 `json:"type"`
+	Time   string                 `json:"time"`
+	Data   map[string]interface{} `json:"data,omitempty"`
+}
+
+// Report is the single document written at the end of a command in json mode
+type Report struct {
+	Schema     int          `json:"schema"`
+	Command    string       `json:"command"`
+	Success    bool         `json:"success"`
+	ExitCode   int          `json:"exitCode"`
+	Error      *ReportError `json:"error,omitempty"`
+	StartedAt  string       `json:"startedAt"`
+	DurationMs int64        `json:"durationMs"`
+	Events     []Event      `json:"events"`
+}
+
+// ReportError describes the error that ended a command
+type ReportError struct {
+	Kind    ErrorKind `json:"kind"`
+	Message string    `json:"message"`
+	Hint    string    `json:"hint,omitempty"`
+}
+
+// EventSink collects and writes machine-readable events
+// In text mode it is a no-op; in ndjson mode every event is written as it happens;
+// in json mode events are buffered and written as one Report when the command ends
+type EventSink struct {
+	mu      sync.Mutex
+	mode    string
+	out     io.Writer
+	command string
+	started time.Time
+	events  []Event
+	closed  bool
+}
+
+// NewEventSink creates a sink writing machine-readable output to out
+func NewEventSink(mode string, out io.Writer) *EventSink {
+	return &EventSink{mode: mode, out: out, started: time.Now()}
+}
+
+// Machine reports whether a machine-readable mode is active
+func (s *EventSink) Machine() bool {
+	return s.mode == OutputJSON || s.mode == OutputNDJSON
+}
+
+// Begin records the command being run
+func (s *EventSink) Begin(command string) {
+	s.mu.Lock()
+	s.command = command
+	s.started = time.Now()
+	s.mu.Unlock()
+	s.Emit(EventCommandStarted, map[string]interface{}{"command": command})
+}
+
+// Emit records an event. It is safe to call from several goroutines
+func (s *EventSink) Emit(eventType string, data map[string]interface{}) {
+	if !s.Machine() {
+		return
+	}
+
+	event := Event{
+		Schema: OutputSchemaVersion,
+		Type:   eventType,
+		Time:   time.Now().UTC().Format(time.RFC3339Nano),
+		Data:   data,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if s.mode == OutputNDJSON {
+		line, err := json.Marshal(event)
+		if err == nil {
+			s.out.Write(append(line, '\n'))
+		}
+		return
+	}
+	s.events = append(s.events, event)
+}
+
+// Close emits the final command.completed event and, in json mode, writes the report
+// Further events are ignored
+func (s *EventSink) Close(exitCode int, cliErr *CLIError) {
+	if !s.Machine() {
+		return
+	}
+
+	success := cliErr == nil
+	var reportErr *ReportError
+	if cliErr != nil {
+		reportErr = &ReportError{Kind: cliErr.Kind, Message: cliErr.Error(), Hint: cliErr.Hint}
+	}
+	data := map[string]interface{}{
+		"success":    success,
+		"exitCode":   exitCode,
+		"durationMs": time.Since(s.started).Milliseconds(),
+	}
+	if reportErr != nil {
+		data["error"] = reportErr
+	}
+	s.Emit(EventCommandCompleted, data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+
+	if s.mode != OutputJSON {
+		return
+	}
+	events := s.events
+	if events == nil {
+		events = []Event{}
+	}
+	report := Report{
+		Schema:     OutputSchemaVersion,
+		Command:    s.command,
+		Success:    success,
+		ExitCode:   exitCode,
+		Error:      reportErr,
+		StartedAt:  s.started.UTC().Format(time.RFC3339Nano),
+		DurationMs: time.Since(s.started).Milliseconds(),
+		Events:     events,
+	}
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		s.out.Write(append(encoded, '\n'))
+	}
+}
+
+// setupOutput configures the event sink for the selected mode
+// In machine-readable modes stdout is reserved for events, so human-readable
+// output is redirected to stderr
+func (c *CLITool) setupOutput(mode string) {
+	c.events = NewEventSink(mode, os.Stdout)
+	if c.events.Machine() {
+		os.Stdout = os.Stderr
+	}
+}
+
+// classifyInstallError maps package manager output to a stable error class
+func classifyInstallError(output string) string {
+	switch {
+	case strings.Contains(output, "E404") || strings.Contains(output, "404 Not Found") || strings.Contains(output, "not found"):
+		return "not_found"
+	case strings.Contains(output, "ETIMEDOUT") || strings.Contains(output, "ENOTFOUND") ||
+		strings.Contains(output, "ECONNRESET") || strings.Contains(output, "EAI_AGAIN") ||
+		strings.Contains(output, "ECONNREFUSED"):
+		return "network"
+	case strings.Contains(output, "EACCES") || strings.Contains(output, "EPERM"):
+		return "permission"
+	case strings.Contains(output, "ERESOLVE") || strings.Contains(output, "peer dep"):
+		return "dependency_conflict"
+	case strings.Contains(output, "ENOENT") || strings.Contains(output, "ENOTEMPTY") || strings.Contains(output, "ENOSPC"):
+		return "filesystem"
+	case strings.Contains(output, "ELIFECYCLE") || strings.Contains(output, "postinstall"):
+		return "lifecycle_script"
+	}
+	return "unknown"
+}
@@ -0,0 +1,1086 @@
+package modules
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// GeneratorsDir holds the project's generator templates (<kind>.<lang>.tmpl), used instead of the built-in ones
+const GeneratorsDir = ".xypcli/generators"
+
+// Files the generated routes and middleware are registered in
+const (
+	RoutesIndexFile     = "src/routes/index"
+	MiddlewareIndexFile = "src/middleware/index"
+)
+
+// httpMethods are the methods accepted by "generate route --methods"
+var httpMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// generatorKinds lists the generators with the file they write (without extension)
+var generatorKinds = []struct {
+	Kind string
+	File string // Path relative to the project, %s being the kebab-case name
+}{
+	{"route", "src/routes/%s.route"},
+	{"middleware", "src/middleware/%s.middleware"},
+	{"schema", "src/schema/%s.schema"},
+	{"validation", "src/middleware/%s-validation.middleware"},
+	{"repository", "src/repositories/%s.repository"},
+	{"resource", "src/routes/%s.route"},
+	{"test", "tests/%s.test"},
+}
+
+// generatorMethod is an HTTP method of a generated route
+type generatorMethod struct {
+	Name string // Upper case, e.g. "GET"
+	Func string // Router function, e.g. "get"
+}
+
+// generatorData is the data passed to generator templates
+type generatorData struct {
+	Name    string // Name as given, e.g. "user profile"
+	File    string // kebab-case, e.g. "user-profile"
+	Camel   string // e.g. "userProfile"
+	Pascal  string // e.g. "UserProfile"
+	Path    string // Mount path of a route, e.g. "/user-profile"
+	Methods []generatorMethod
+	Fields  []generatorField // Fields of a schema or resource
+	Lang    string           // "ts" or "js"
+	ESM     bool             // ES modules (import/export) rather than CommonJS (require/module.exports)
+}
+
+// generatorField is a field of a generated schema or resource
+type generatorField struct {
+	Name     string
+	Type     string // fortify-schema type without the optional mark, e.g. "string"
+	Optional bool
+	Schema   string // fortify-schema type, e.g. "string?"
+	TSType   string // TypeScript type, e.g. "string"
+	Sample   string // Valid value, as a JavaScript literal
+	Invalid  string // Value of the wrong type, as a JavaScript literal
+}
+
+// fieldTypes maps the types accepted by --fields, and their aliases, to fortify-schema types
+var fieldTypes = map[string]string{
+	"string":  "string",
+	"text":    "string",
+	"number":  "number",
+	"int":     "number",
+	"integer": "number",
+	"float":   "number",
+	"boolean": "boolean",
+	"bool":    "boolean",
+	"email":   "email",
+	"url":     "url",
+}
+
+// fieldSamples are a valid and an invalid value of each fortify-schema type, used by generated tests
+var fieldSamples = map[string][2]string{
+	"string":  {`"example"`, `123`},
+	"number":  {`1`, `"one"`},
+	"boolean": {`true`, `"yes"`},
+	"email":   {`"user@example.com"`, `"not-an-email"`},
+	"url":     {`"https://example.com"`, `"not a url"`},
+}
+
+// fieldNamePattern matches the field names accepted by --fields
+var fieldNamePattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// parseFields reads the --fields list: name:type pairs, a type ending with ? being optional
+// (e.g. "title:string,done:bool,notes:string?"); the type defaults to string
+func parseFields(list string) ([]generatorField, error) {
+	fields := []generatorField{}
+	seen := map[string]bool{}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, kind := item, "string"
+		if colon := strings.Index(item, ":"); colon >= 0 {
+			name, kind = strings.TrimSpace(item[:colon]), strings.ToLower(strings.TrimSpace(item[colon+1:]))
+		}
+		field := generatorField{Name: name, Optional: strings.HasSuffix(kind, "?")}
+		switch {
+		case !fieldNamePattern.MatchString(name):
+			return nil, usageError("invalid field name '%s'", name).
+				WithHint("Use a JavaScript identifier (e.g. createdBy)")
+		case name == "id":
+			return nil, usageError("the id field is added by the repository, remove it from --fields")
+		case seen[name]:
+			return nil, usageError("field '%s' is listed twice", name)
+		}
+		seen[name] = true
+
+		schemaType, ok := fieldTypes[strings.TrimSuffix(kind, "?")]
+		if !ok {
+			return nil, usageError("unknown type '%s' for field %s", kind, name).
+				WithHint("Valid types: string, number, boolean, email, url (add ? for an optional field, e.g. notes:string?)")
+		}
+		field.Type, field.Schema, field.TSType = schemaType, schemaType, "string"
+		if field.Optional {
+			field.Schema += "?"
+		}
+		if schemaType == "number" || schemaType == "boolean" {
+			field.TSType = schemaType
+		}
+		field.Sample, field.Invalid = fieldSamples[schemaType][0], fieldSamples[schemaType][1]
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// pluralize returns the plural of an English kebab-case name, for the mount path of a resource
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "z"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
+
+// generatorNamePattern matches the names accepted by the generators
+var generatorNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9 _-]*$`)
+
+// nameWords splits a name into lower-case words at spaces, dashes, underscores and case changes
+func nameWords(name string) []string {
+	words := []string{}
+	current := []rune{}
+	runes := []rune(name)
+	for i, r := range runes {
+		if r == ' ' || r == '-' || r == '_' {
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			words = append(words, string(current))
+			current = nil
+		}
+		current = append(current, unicode.ToLower(r))
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+	return words
+}
+
+// newGeneratorData derives the names used by the templates, and detects the language of the project
+func newGeneratorData(name, lang string) (generatorData, error) {
+	name = strings.TrimSpace(name)
+	if !generatorNamePattern.MatchString(name) {
+		return generatorData{}, usageError("invalid name '%s'", name).
+			WithHint("Use letters, digits, spaces, dashes and underscores, starting with a letter (e.g. user-profile)")
+	}
+	words := nameWords(name)
+	data := generatorData{Name: name, File: strings.Join(words, "-"), Lang: lang}
+	for i, word := range words {
+		title := strings.ToUpper(word[:1]) + word[1:]
+		data.Pascal += title
+		if i == 0 {
+			data.Camel += word
+		} else {
+			data.Camel += title
+		}
+	}
+	data.Path = "/" + data.File
+
+	if data.Lang == "" {
+		data.Lang = projectLanguage(".")
+	}
+	data.ESM = data.Lang == "ts" || usesESM(".")
+	return data, nil
+}
+
+// usesESM reports whether the JavaScript of dir uses ES modules: the index files decide,
+// then the "type" field of package.json
+func usesESM(dir string) bool {
+	for _, name := range []string{RoutesIndexFile, MiddlewareIndexFile} {
+		index, err := os.ReadFile(filepath.Join(dir, name+".js"))
+		if err != nil {
+			continue
+		}
+		if bytes.Contains(index, []byte("require(")) || bytes.Contains(index, []byte("module.exports")) {
+			return false
+		}
+		return true
+	}
+	pkg, _ := readPackageJSON(dir)
+	return pkg.Type == "module"
+}
+
+// projectLanguage returns "ts" for TypeScript projects and "js" otherwise
+func projectLanguage(dir string) string {
+	for _, name := range []string{"tsconfig.json", "src/server.ts", RoutesIndexFile + ".ts"} {
+		if fileExists(filepath.Join(dir, name)) {
+			return "ts"
+		}
+	}
+	return "js"
+}
+
+// parseMethods reads the --methods list (e.g. GET,POST)
+func parseMethods(list string) ([]generatorMethod, error) {
+	methods := []generatorMethod{}
+	seen := map[string]bool{}
+	for _, method := range strings.Split(list, ",") {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == "" || seen[method] {
+			continue
+		}
+		if !containsString(httpMethods, method) {
+			return nil, usageError("unknown HTTP method '%s'", method).
+				WithHint("Valid methods: %s", strings.Join(httpMethods, ", "))
+		}
+		seen[method] = true
+		methods = append(methods, generatorMethod{Name: method, Func: strings.ToLower(method)})
+	}
+	if len(methods) == 0 {
+		return nil, usageError("--methods needs at least one HTTP method")
+	}
+	return methods, nil
+}
+
+// generatorTemplate returns the template of kind for lang: the project's override, or the built-in one
+func generatorTemplate(kind, lang string) (*template.Template, string, error) {
+	name := kind + "." + lang + ".tmpl"
+	override := filepath.Join(GeneratorsDir, name)
+	text, source := generatorTemplates[kind+"."+lang], "built-in"
+	if data, err := os.ReadFile(override); err == nil {
+		text, source = string(data), override
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, "", usageError("invalid template %s: %v", source, err)
+	}
+	return tmpl, source, nil
+}
+
+// renderGenerator writes the file of kind; an existing file is only replaced with force
+func (c *CLITool) renderGenerator(kind, path string, data generatorData, force bool) error {
+	if fileExists(path) && !force {
+		return usageError("file %s already exists", path).
+			WithHint("Use --force to overwrite it")
+	}
+	tmpl, source, err := generatorTemplate(kind, data.Lang)
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return usageError("cannot render template %s: %v", source, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return failureError(err, "failed to create %s", filepath.Dir(path))
+	}
+	if err := os.WriteFile(path, buffer.Bytes(), 0644); err != nil {
+		return failureError(err, "failed to write %s", path)
+	}
+	note := ""
+	if source != "built-in" {
+		note = fmt.Sprintf(" %s(template: %s)%s", ColorDim, source, ColorReset)
+	}
+	printf("%s✓ Created %s%s%s\n", ColorGreen, path, ColorReset, note)
+	return nil
+}
+
+// generatorPath returns the file written by kind for data
+func generatorPath(kind string, data generatorData) string {
+	for _, generator := range generatorKinds {
+		if generator.Kind == kind {
+			return fmt.Sprintf(generator.File, data.File) + "." + data.Lang
+		}
+	}
+	return ""
+}
+
+// importLine returns the statement importing the default export (or the named export) of module
+func importLine(data generatorData, identifier, module string, named bool) string {
+	switch {
+	case !data.ESM && named:
+		return fmt.Sprintf("const { %s } = require(\"%s\");", identifier, module)
+	case !data.ESM:
+		return fmt.Sprintf("const %s = require(\"%s\");", identifier, module)
+	}
+	if data.Lang == "js" {
+		module += ".js"
+	}
+	if named {
+		return fmt.Sprintf("import { %s } from \"%s\";", identifier, module)
+	}
+	return fmt.Sprintf("import %s from \"%s\";", identifier, module)
+}
+
+// importPattern matches the lines ending an import (or a require) at the top of a module
+var importPattern = regexp.MustCompile(`^(import\s|\}\s*from\s|(const|let|var)\s.*=\s*require\()`)
+
+// addImport inserts an import after the last one of lines, or after the leading comment
+func addImport(lines []string, line string) []string {
+	at := -1
+	for i, text := range lines {
+		if importPattern.MatchString(text) {
+			at = i + 1
+		}
+	}
+	if at < 0 {
+		if !strings.HasPrefix(strings.TrimSpace(lines[0]), "/*") {
+			return insertLines(lines, 0, line, "")
+		}
+		at = 0
+		for at < len(lines) && !strings.Contains(lines[at], "*/") {
+			at++
+		}
+		return insertLines(lines, at+1, "", line)
+	}
+	return insertLines(lines, at, line)
+}
+
+// insertLines inserts text before lines[at]
+func insertLines(lines []string, at int, text ...string) []string {
+	if at > len(lines) {
+		at = len(lines)
+	}
+	result := make([]string, 0, len(lines)+len(text))
+	result = append(result, lines[:at]...)
+	result = append(result, text...)
+	return append(result, lines[at:]...)
+}
+
+// lastCodeLine returns the index of the last non-blank line before end
+func lastCodeLine(lines []string, end int) int {
+	i := end - 1
+	for i >= 0 && strings.TrimSpace(lines[i]) == "" {
+		i--
+	}
+	return i
+}
+
+// editIndex applies edit to the lines of an index file; the file is left untouched when
+// it already imports module or when edit cannot find where the statement goes
+func editIndex(path, module string, edit func([]string) ([]string, bool)) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, nil
+	}
+	if bytes.Contains(data, []byte("\""+module+"\"")) || bytes.Contains(data, []byte("\""+module+".js\"")) {
+		return true, nil
+	}
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	trailing := strings.HasSuffix(content, "\n")
+	lines, ok := edit(strings.Split(strings.TrimSuffix(content, "\n"), "\n"))
+	if !ok {
+		return false, nil
+	}
+	content = strings.Join(lines, "\n")
+	if trailing {
+		content += "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return false, failureError(err, "failed to write %s", path)
+	}
+	return true, nil
+}
+
+// routerExportPattern matches the export of the main router
+var routerExportPattern = regexp.MustCompile(`^(export\s+default\s+router\b|module\.exports\s*=\s*router\b)`)
+
+// registerRoute mounts a generated router in src/routes/index, before the export of the main router
+func registerRoute(data generatorData) (bool, error) {
+	identifier := data.Camel + "Router"
+	module := "./" + data.File + ".route"
+	mount := fmt.Sprintf("router.use(\"%s\", %s);", data.Path, identifier)
+	return editIndex(RoutesIndexFile+"."+data.Lang, module, func(lines []string) ([]string, bool) {
+		for i, line := range lines {
+			if !routerExportPattern.MatchString(line) {
+				continue
+			}
+			// Keep generated mounts together, separated from the code above by a blank line
+			previous := lastCodeLine(lines, i)
+			if previous >= 0 && strings.HasPrefix(lines[previous], "router.use(") {
+				lines = insertLines(lines, previous+1, mount)
+			} else {
+				lines = insertLines(lines, previous+1, "", mount)
+			}
+			return addImport(lines, importLine(data, identifier, module, false)), true
+		}
+		return nil, false
+	})
+}
+
+// setupMiddlewarePattern matches the declaration of setupMiddleware
+var setupMiddlewarePattern = regexp.MustCompile(`\bfunction\s+setupMiddleware\s*\(`)
+
+// registerMiddleware adds a generated middleware to setupMiddleware in src/middleware/index,
+// before its closing log line if it has one
+func registerMiddleware(data generatorData) (bool, error) {
+	identifier := data.Camel + "Middleware"
+	module := "./" + data.File + ".middleware"
+	return editIndex(MiddlewareIndexFile+"."+data.Lang, module, func(lines []string) ([]string, bool) {
+		start := -1
+		for i, line := range lines {
+			if setupMiddlewarePattern.MatchString(line) {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return nil, false
+		}
+		for end := start + 1; end < len(lines); end++ {
+			if lines[end] != "}" {
+				continue
+			}
+			use := fmt.Sprintf("  app.use(%s);", identifier)
+			last := lastCodeLine(lines, end)
+			if strings.Contains(lines[last], "console.log(") {
+				last = lastCodeLine(lines, last)
+			}
+			if isUseLine(lines[last]) || strings.HasSuffix(strings.TrimSpace(lines[last]), "{") {
+				lines = insertLines(lines, last+1, use)
+			} else {
+				lines = insertLines(lines, last+1, "", use)
+			}
+			return addImport(lines, importLine(data, identifier, module, true)), true
+		}
+		return nil, false
+	})
+}
+
+// isUseLine reports whether line registers a middleware in one statement, e.g. app.use(timer);
+func isUseLine(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "app.use(") && strings.HasSuffix(line, ");")
+}
+
+// GenerateRoute writes a router and mounts it in src/routes/index
+func (c *CLITool) GenerateRoute(name, methods, path, lang string, force bool) error {
+	if err := requireProject(); err != nil {
+		return err
+	}
+	data, err := newGeneratorData(name, lang)
+	if err != nil {
+		return err
+	}
+	if data.Methods, err = parseMethods(orDefault(methods, "GET")); err != nil {
+		return err
+	}
+	if path != "" {
+		data.Path = "/" + strings.Trim(strings.TrimSpace(path), "/")
+	}
+	if err := c.renderGenerator("route", generatorPath("route", data), data, force); err != nil {
+		return err
+	}
+	registered, err := registerRoute(data)
+	if err != nil {
+		return err
+	}
+	index := RoutesIndexFile + "." + data.Lang
+	if registered {
+		printf("  %s→ Mounted on %s in %s%s\n", ColorDim, data.Path, index, ColorReset)
+		return nil
+	}
+	printf("%s⚠ Could not register the route in %s: mount %sRouter yourself%s\n", ColorYellow, index, data.Camel, ColorReset)
+	return nil
+}
+
+// GenerateMiddleware writes a middleware and adds it to setupMiddleware in src/middleware/index
+func (c *CLITool) GenerateMiddleware(name, lang string, force bool) error {
+	if err := requireProject(); err != nil {
+		return err
+	}
+	data, err := newGeneratorData(name, lang)
+	if err != nil {
+		return err
+	}
+	if err := c.renderGenerator("middleware", generatorPath("middleware", data), data, force); err != nil {
+		return err
+	}
+	registered, err := registerMiddleware(data)
+	if err != nil {
+		return err
+	}
+	index := MiddlewareIndexFile + "." + data.Lang
+	if registered {
+		printf("  %s→ Registered in setupMiddleware (%s)%s\n", ColorDim, index, ColorReset)
+		return nil
+	}
+	printf("%s⚠ Could not register the middleware in %s: add app.use(%sMiddleware) yourself%s\n", ColorYellow, index, data.Camel, ColorReset)
+	return nil
+}
+
+// GenerateSchema writes a fortify-schema interface
+func (c *CLITool) GenerateSchema(name, fields, lang string, force bool) error {
+	if err := requireProject(); err != nil {
+		return err
+	}
+	data, err := newGeneratorData(name, lang)
+	if err != nil {
+		return err
+	}
+	if data.Fields, err = parseFields(fields); err != nil {
+		return err
+	}
+	return c.renderGenerator("schema", generatorPath("schema", data), data, force)
+}
+
+// GenerateResource writes a schema, its validation middleware, an in-memory repository,
+// a CRUD router mounted in src/routes/index, and a test file
+func (c *CLITool) GenerateResource(name, fields, path, lang string, force bool) error {
+	if err := requireProject(); err != nil {
+		return err
+	}
+	data, err := newGeneratorData(name, lang)
+	if err != nil {
+		return err
+	}
+	if data.Fields, err = parseFields(fields); err != nil {
+		return err
+	}
+	if len(data.Fields) == 0 {
+		return usageError("--fields is required").
+			WithHint("List the fields of the resource, e.g. --fields \"title:string,done:bool\"")
+	}
+	data.Path = "/" + pluralize(data.File)
+	if path != "" {
+		data.Path = "/" + strings.Trim(strings.TrimSpace(path), "/")
+	}
+	data.Methods, _ = parseMethods("GET,POST,PUT,DELETE")
+
+	// Check every file first, so that nothing is written when one of them exists
+	kinds := []string{"schema", "validation", "repository", "resource", "test"}
+	for _, kind := range kinds {
+		if file := generatorPath(kind, data); fileExists(file) && !force {
+			return usageError("file %s already exists", file).
+				WithHint("Use --force to overwrite the files of the resource")
+		}
+	}
+	for _, kind := range kinds {
+		if err := c.renderGenerator(kind, generatorPath(kind, data), data, true); err != nil {
+			return err
+		}
+	}
+
+	registered, err := registerRoute(data)
+	if err != nil {
+		return err
+	}
+	index := RoutesIndexFile + "." + data.Lang
+	if !registered {
+		printf("%s⚠ Could not register the route in %s: mount %sRouter yourself%s\n", ColorYellow, index, data.Camel, ColorReset)
+		return nil
+	}
+	printf("  %s→ Mounted on %s in %s%s\n", ColorDim, data.Path, index, ColorReset)
+	printf("  %s→ GET %[2]s, GET %[2]s/:id, POST %[2]s, PUT %[2]s/:id, DELETE %[2]s/:id%[3]s\n", ColorDim, data.Path, ColorReset)
+	return nil
+}
+
+// GenerateTemplates copies the built-in templates to .xypcli/generators, keeping existing ones
+func (c *CLITool) GenerateTemplates(force bool) error {
+	if err := requireProject(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(GeneratorsDir, 0755); err != nil {
+		return failureError(err, "failed to create %s", GeneratorsDir)
+	}
+	names := make([]string, 0, len(generatorTemplates))
+	for name := range generatorTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(GeneratorsDir, name+".tmpl")
+		if fileExists(path) && !force {
+			printf("%s%s already exists%s\n", ColorDim, path, ColorReset)
+			continue
+		}
+		if err := os.WriteFile(path, []byte(generatorTemplates[name]), 0644); err != nil {
+			return failureError(err, "failed to write %s", path)
+		}
+		printf("%s✓ Created %s%s\n", ColorGreen, path, ColorReset)
+	}
+	return nil
+}
+
+// generatorTemplates are the built-in templates, by <kind>.<lang>
+// They are Go text/template documents receiving a generatorData
+var generatorTemplates = map[string]string{
+	"route.ts": `/**
+ * {{.Pascal}} Routes
+ *
+ * Mounted on {{.Path}} in src/routes/index.ts.
+ */
+
+import { Router, type Request, type Response } from "xypriss";
+
+const router = Router();
+{{range .Methods}}
+router.{{.Func}}("/", (req: Request, res: Response) => {
+  // TODO: Implement {{.Name}} {{$.Path}}
+  res{{if eq .Name "POST"}}.status(201){{end}}.json({ message: "{{.Name}} {{$.Path}}" });
+});
+{{end}}
+export default router;
+`,
+	"route.js": `/**
+ * {{.Pascal}} Routes
+ *
+ * Mounted on {{.Path}} in src/routes/index.js.
+ */
+
+{{if .ESM}}import { Router } from "xypriss";{{else}}const { Router } = require("xypriss");{{end}}
+
+const router = Router();
+{{range .Methods}}
+router.{{.Func}}("/", (req, res) => {
+  // TODO: Implement {{.Name}} {{$.Path}}
+  res{{if eq .Name "POST"}}.status(201){{end}}.json({ message: "{{.Name}} {{$.Path}}" });
+});
+{{end}}
+{{if .ESM}}export default router;{{else}}module.exports = router;{{end}}
+`,
+	"middleware.ts": `/**
+ * {{.Pascal}} Middleware
+ *
+ * Registered in setupMiddleware (src/middleware/index.ts).
+ */
+
+import type { Request, Response, NextFunction } from "xypriss";
+
+export function {{.Camel}}Middleware(req: Request, res: Response, next: NextFunction) {
+  // TODO: Implement the {{.Name}} middleware
+  next();
+}
+`,
+	"middleware.js": `/**
+ * {{.Pascal}} Middleware
+ *
+ * Registered in setupMiddleware (src/middleware/index.js).
+ */
+
+{{if .ESM}}export {{end}}function {{.Camel}}Middleware(req, res, next) {
+  // TODO: Implement the {{.Name}} middleware
+  next();
+}
+{{if not .ESM}}
+module.exports = { {{.Camel}}Middleware };
+{{end}}`,
+	"schema.ts": `import { Interface } from "fortify-schema";
+
+export const {{.Camel}}Schema = Interface({
+{{- range .Fields}}
+  {{.Name}}: "{{.Schema}}",
+{{- else}}
+  // TODO: Describe the {{.Name}} fields (e.g. email: "email")
+  id: "string",
+{{- end}}
+});
+`,
+	"schema.js": `{{if .ESM}}import { Interface } from "fortify-schema";{{else}}const { Interface } = require("fortify-schema");{{end}}
+
+{{if .ESM}}export {{end}}const {{.Camel}}Schema = Interface({
+{{- range .Fields}}
+  {{.Name}}: "{{.Schema}}",
+{{- else}}
+  // TODO: Describe the {{.Name}} fields (e.g. email: "email")
+  id: "string",
+{{- end}}
+});
+{{if not .ESM}}
+module.exports = { {{.Camel}}Schema };
+{{end}}`,
+	"validation.ts": `/**
+ * {{.Pascal}} Validation Middleware
+ *
+ * Validates request bodies against {{.Camel}}Schema, like validateBody() in validation.middleware.ts.
+ */
+
+import type { Request, Response, NextFunction } from "xypriss";
+import { {{.Camel}}Schema } from "../schema/{{.File}}.schema";
+
+export function validate{{.Pascal}}(req: Request, res: Response, next: NextFunction) {
+  try {
+    const result = {{.Camel}}Schema.safeParse(req.body);
+
+    if (result.success) {
+      // Replace request data with validated data
+      (req as any).body = result.data;
+      next();
+    } else {
+      res.status(400).json({
+        error: {
+          message: "Validation failed",
+          details: result.errors,
+          requestId: (req as any).requestId,
+        },
+      });
+    }
+  } catch (error) {
+    next(error);
+  }
+}
+`,
+	"validation.js": `/**
+ * {{.Pascal}} Validation Middleware
+ *
+ * Validates request bodies against {{.Camel}}Schema, like validateBody() in validation.middleware.js.
+ */
+
+{{if .ESM}}import { {{.Camel}}Schema } from "../schema/{{.File}}.schema.js";{{else}}const { {{.Camel}}Schema } = require("../schema/{{.File}}.schema");{{end}}
+
+{{if .ESM}}export {{end}}function validate{{.Pascal}}(req, res, next) {
+  try {
+    const result = {{.Camel}}Schema.safeParse(req.body);
+
+    if (result.success) {
+      // Replace request data with validated data
+      req.body = result.data;
+      next();
+    } else {
+      res.status(400).json({
+        error: {
+          message: "Validation failed",
+          details: result.errors,
+          requestId: req.requestId,
+        },
+      });
+    }
+  } catch (error) {
+    next(error);
+  }
+}
+{{if not .ESM}}
+module.exports = { validate{{.Pascal}} };
+{{end}}`,
+	"repository.ts": `/**
+ * {{.Pascal}} Repository
+ *
+ * Storage of the {{.Name}} resource. The routes use the in-memory implementation until
+ * another one (e.g. backed by a database) is plugged in with use{{.Pascal}}Repository().
+ */
+
+import { randomUUID } from "node:crypto";
+
+export interface {{.Pascal}} {
+  id: string;
+{{- range .Fields}}
+  {{.Name}}{{if .Optional}}?{{end}}: {{.TSType}};
+{{- end}}
+}
+
+export type {{.Pascal}}Input = Omit<{{.Pascal}}, "id">;
+
+export interface {{.Pascal}}Repository {
+  list(): Promise<{{.Pascal}}[]>;
+  get(id: string): Promise<{{.Pascal}} | undefined>;
+  create(input: {{.Pascal}}Input): Promise<{{.Pascal}}>;
+  update(id: string, input: {{.Pascal}}Input): Promise<{{.Pascal}} | undefined>;
+  delete(id: string): Promise<boolean>;
+}
+
+export class InMemory{{.Pascal}}Repository implements {{.Pascal}}Repository {
+  private items = new Map<string, {{.Pascal}}>();
+
+  async list(): Promise<{{.Pascal}}[]> {
+    return [...this.items.values()];
+  }
+
+  async get(id: string): Promise<{{.Pascal}} | undefined> {
+    return this.items.get(id);
+  }
+
+  async create(input: {{.Pascal}}Input): Promise<{{.Pascal}}> {
+    const item = { ...input, id: randomUUID() };
+    this.items.set(item.id, item);
+    return item;
+  }
+
+  async update(id: string, input: {{.Pascal}}Input): Promise<{{.Pascal}} | undefined> {
+    if (!this.items.has(id)) {
+      return undefined;
+    }
+    const item = { ...input, id };
+    this.items.set(id, item);
+    return item;
+  }
+
+  async delete(id: string): Promise<boolean> {
+    return this.items.delete(id);
+  }
+}
+
+let repository: {{.Pascal}}Repository = new InMemory{{.Pascal}}Repository();
+
+/** Returns the repository used by the {{.Name}} routes */
+export function get{{.Pascal}}Repository(): {{.Pascal}}Repository {
+  return repository;
+}
+
+/** Replaces the repository used by the {{.Name}} routes (e.g. at startup, or in tests) */
+export function use{{.Pascal}}Repository(next: {{.Pascal}}Repository): void {
+  repository = next;
+}
+`,
+	"repository.js": `/**
+ * {{.Pascal}} Repository
+ *
+ * Storage of the {{.Name}} resource. The routes use the in-memory implementation until
+ * another one (e.g. backed by a database) is plugged in with use{{.Pascal}}Repository().
+ * A repository has async list(), get(id), create(input), update(id, input) and delete(id).
+ */
+
+{{if .ESM}}import { randomUUID } from "node:crypto";{{else}}const { randomUUID } = require("node:crypto");{{end}}
+
+{{if .ESM}}export {{end}}class InMemory{{.Pascal}}Repository {
+  items = new Map();
+
+  async list() {
+    return [...this.items.values()];
+  }
+
+  async get(id) {
+    return this.items.get(id);
+  }
+
+  async create(input) {
+    const item = { ...input, id: randomUUID() };
+    this.items.set(item.id, item);
+    return item;
+  }
+
+  async update(id, input) {
+    if (!this.items.has(id)) {
+      return undefined;
+    }
+    const item = { ...input, id };
+    this.items.set(id, item);
+    return item;
+  }
+
+  async delete(id) {
+    return this.items.delete(id);
+  }
+}
+
+let repository = new InMemory{{.Pascal}}Repository();
+
+/** Returns the repository used by the {{.Name}} routes */
+{{if .ESM}}export {{end}}function get{{.Pascal}}Repository() {
+  return repository;
+}
+
+/** Replaces the repository used by the {{.Name}} routes (e.g. at startup, or in tests) */
+{{if .ESM}}export {{end}}function use{{.Pascal}}Repository(next) {
+  repository = next;
+}
+{{if not .ESM}}
+module.exports = { InMemory{{.Pascal}}Repository, get{{.Pascal}}Repository, use{{.Pascal}}Repository };
+{{end}}`,
+	"resource.ts": `/**
+ * {{.Pascal}} Routes
+ *
+ * CRUD endpoints of the {{.Name}} resource, mounted on {{.Path}} in src/routes/index.ts.
+ */
+
+import { Router, type Request, type Response } from "xypriss";
+import { validate{{.Pascal}} } from "../middleware/{{.File}}-validation.middleware";
+import { get{{.Pascal}}Repository } from "../repositories/{{.File}}.repository";
+
+const router = Router();
+
+function notFound(res: Response) {
+  res.status(404).json({ error: { message: "{{.Pascal}} not found" } });
+}
+
+// List
+router.get("/", async (req: Request, res: Response) => {
+  res.json(await get{{.Pascal}}Repository().list());
+});
+
+// Get
+router.get("/:id", async (req: Request, res: Response) => {
+  const item = await get{{.Pascal}}Repository().get(req.params.id);
+  if (!item) {
+    return notFound(res);
+  }
+  res.json(item);
+});
+
+// Create
+router.post("/", validate{{.Pascal}}, async (req: Request, res: Response) => {
+  res.status(201).json(await get{{.Pascal}}Repository().create(req.body));
+});
+
+// Update
+router.put("/:id", validate{{.Pascal}}, async (req: Request, res: Response) => {
+  const item = await get{{.Pascal}}Repository().update(req.params.id, req.body);
+  if (!item) {
+    return notFound(res);
+  }
+  res.json(item);
+});
+
+// Delete
+router.delete("/:id", async (req: Request, res: Response) => {
+  if (!(await get{{.Pascal}}Repository().delete(req.params.id))) {
+    return notFound(res);
+  }
+  res.status(204).end();
+});
+
+export default router;
+`,
+	"resource.js": `/**
+ * {{.Pascal}} Routes
+ *
+ * CRUD endpoints of the {{.Name}} resource, mounted on {{.Path}} in src/routes/index.js.
+ */
+
+{{if .ESM}}import { Router } from "xypriss";
+import { validate{{.Pascal}} } from "../middleware/{{.File}}-validation.middleware.js";
+import { get{{.Pascal}}Repository } from "../repositories/{{.File}}.repository.js";{{else}}const { Router } = require("xypriss");
+const { validate{{.Pascal}} } = require("../middleware/{{.File}}-validation.middleware");
+const { get{{.Pascal}}Repository } = require("../repositories/{{.File}}.repository");{{end}}
+
+const router = Router();
+
+function notFound(res) {
+  res.status(404).json({ error: { message: "{{.Pascal}} not found" } });
+}
+
+// List
+router.get("/", async (req, res) => {
+  res.json(await get{{.Pascal}}Repository().list());
+});
+
+// Get
+router.get("/:id", async (req, res) => {
+  const item = await get{{.Pascal}}Repository().get(req.params.id);
+  if (!item) {
+    return notFound(res);
+  }
+  res.json(item);
+});
+
+// Create
+router.post("/", validate{{.Pascal}}, async (req, res) => {
+  res.status(201).json(await get{{.Pascal}}Repository().create(req.body));
+});
+
+// Update
+router.put("/:id", validate{{.Pascal}}, async (req, res) => {
+  const item = await get{{.Pascal}}Repository().update(req.params.id, req.body);
+  if (!item) {
+    return notFound(res);
+  }
+  res.json(item);
+});
+
+// Delete
+router.delete("/:id", async (req, res) => {
+  if (!(await get{{.Pascal}}Repository().delete(req.params.id))) {
+    return notFound(res);
+  }
+  res.status(204).end();
+});
+
+{{if .ESM}}export default router;{{else}}module.exports = router;{{end}}
+`,
+	"test.ts": `/**
+ * {{.Pascal}} Resource Tests
+ *
+ * Run with: npx tsx --test tests/{{.File}}.test.ts (or bun test)
+ */
+
+import { test } from "node:test";
+import assert from "node:assert/strict";
+import { {{.Camel}}Schema } from "../src/schema/{{.File}}.schema";
+import { InMemory{{.Pascal}}Repository, type {{.Pascal}}Input } from "../src/repositories/{{.File}}.repository";
+
+const valid: {{.Pascal}}Input = {
+{{- range .Fields}}
+  {{.Name}}: {{.Sample}},
+{{- end}}
+};
+
+test("{{.Camel}}Schema accepts a valid {{.Name}}", () => {
+  assert.equal({{.Camel}}Schema.safeParse(valid).success, true);
+});
+{{with index .Fields 0}}
+test("{{$.Camel}}Schema rejects an invalid {{.Name}}", () => {
+  assert.equal({{$.Camel}}Schema.safeParse({ ...valid, {{.Name}}: {{.Invalid}} } as any).success, false);
+});
+{{end}}
+test("InMemory{{.Pascal}}Repository creates, reads, updates and deletes", async () => {
+  const repository = new InMemory{{.Pascal}}Repository();
+
+  const created = await repository.create(valid);
+  assert.ok(created.id);
+  assert.deepEqual(await repository.get(created.id), created);
+  assert.equal((await repository.list()).length, 1);
+
+  const updated = await repository.update(created.id, valid);
+  assert.equal(updated?.id, created.id);
+  assert.equal(await repository.update("missing", valid), undefined);
+
+  assert.equal(await repository.delete(created.id), true);
+  assert.equal(await repository.get(created.id), undefined);
+});
+`,
+	"test.js": `/**
+ * {{.Pascal}} Resource Tests
+ *
+ * Run with: node --test tests/{{.File}}.test.js
+ */
+
+{{if .ESM}}import { test } from "node:test";
+import assert from "node:assert/strict";
+import { {{.Camel}}Schema } from "../src/schema/{{.File}}.schema.js";
+import { InMemory{{.Pascal}}Repository } from "../src/repositories/{{.File}}.repository.js";{{else}}const { test } = require("node:test");
+const assert = require("node:assert/strict");
+const { {{.Camel}}Schema } = require("../src/schema/{{.File}}.schema");
+const { InMemory{{.Pascal}}Repository } = require("../src/repositories/{{.File}}.repository");{{end}}
+
+const valid = {
+{{- range .Fields}}
+  {{.Name}}: {{.Sample}},
+{{- end}}
+};
+
+test("{{.Camel}}Schema accepts a valid {{.Name}}", () => {
+  assert.equal({{.Camel}}Schema.safeParse(valid).success, true);
+});
+{{with index .Fields 0}}
+test("{{$.Camel}}Schema rejects an invalid {{.Name}}", () => {
+  assert.equal({{$.Camel}}Schema.safeParse({ ...valid, {{.Name}}: {{.Invalid}} }).success, false);
+});
+{{end}}
+test("InMemory{{.Pascal}}Repository creates, reads, updates and deletes", async () => {
+  const repository = new InMemory{{.Pascal}}Repository();
+
+  const created = await repository.create(valid);
+  assert.ok(created.id);
+  assert.deepEqual(await repository.get(created.id), created);
+  assert.equal((await repository.list()).length, 1);
+
+  const updated = await repository.update(created.id, valid);
+  assert.equal(updated?.id, created.id);
+  assert.equal(await repository.update("missing", valid), undefined);
+
+  assert.equal(await repository.delete(created.id), true);
+  assert.equal(await repository.get(created.id), undefined);
+});
+`,
+}
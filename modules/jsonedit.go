@@ -0,0 +1,355 @@
+package modules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// jsonDocument edits a JSON file in place: only the changed values are rewritten, so key
+// order, indentation, number formatting, the trailing newline and every unrelated byte are kept
+type jsonDocument struct {
+	path string
+	data []byte
+}
+
+// jsonMember is a key of an object and the position of its value
+type jsonMember struct {
+	Key        string
+	KeyStart   int // Offset of the opening quote of the key
+	ValueStart int
+	ValueEnd   int // Offset just after the value
+}
+
+// readJSONDocument reads and checks a JSON file
+func readJSONDocument(path string) (*jsonDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONDocument(path, data)
+}
+
+// parseJSONDocument checks JSON content; the top-level value must be an object
+func parseJSONDocument(path string, data []byte) (*jsonDocument, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %v", path, err)
+	}
+	if _, ok := value.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("%s does not contain a JSON object", path)
+	}
+	return &jsonDocument{path: path, data: data}, nil
+}
+
+// newJSONDocument returns an empty object document
+func newJSONDocument(path string) *jsonDocument {
+	return &jsonDocument{path: path, data: []byte("{\n}\n")}
+}
+
+// skipSpace returns the offset of the first non-whitespace byte from i
+func (d *jsonDocument) skipSpace(i int) int {
+	for i < len(d.data) && strings.IndexByte(" \t\r\n", d.data[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// valueEnd returns the offset just after the value starting at i (the document is valid JSON)
+func (d *jsonDocument) valueEnd(i int) int {
+	switch d.data[i] {
+	case '"':
+		for i++; i < len(d.data); i++ {
+			if d.data[i] == '\\' {
+				i++
+			} else if d.data[i] == '"' {
+				return i + 1
+			}
+		}
+		return i
+	case '{', '[':
+		depth := 0
+		for ; i < len(d.data); i++ {
+			switch d.data[i] {
+			case '"':
+				i = d.valueEnd(i) - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		return i
+	default:
+		for i < len(d.data) && strings.IndexByte(",}] \t\r\n", d.data[i]) < 0 {
+			i++
+		}
+		return i
+	}
+}
+
+// members lists the keys of the object starting at open, and the offset of its closing brace
+func (d *jsonDocument) members(open int) ([]jsonMember, int) {
+	members := []jsonMember{}
+	i := d.skipSpace(open + 1)
+	for i < len(d.data) && d.data[i] != '}' {
+		keyEnd := d.valueEnd(i)
+		var key string
+		json.Unmarshal(d.data[i:keyEnd], &key)
+		valueStart := d.skipSpace(d.skipSpace(keyEnd) + 1) // After the colon
+		valueEnd := d.valueEnd(valueStart)
+		members = append(members, jsonMember{Key: key, KeyStart: i, ValueStart: valueStart, ValueEnd: valueEnd})
+		i = d.skipSpace(valueEnd)
+		if i < len(d.data) && d.data[i] == ',' {
+			i = d.skipSpace(i + 1)
+		}
+	}
+	return members, i
+}
+
+// locate returns the start and end of the value at path ("" path is the whole document)
+func (d *jsonDocument) locate(path []string) (int, int, bool) {
+	start := d.skipSpace(0)
+	end := d.valueEnd(start)
+	for _, key := range path {
+		if d.data[start] != '{' {
+			return 0, 0, false
+		}
+		members, _ := d.members(start)
+		found := false
+		for _, member := range members {
+			if member.Key == key {
+				start, end, found = member.ValueStart, member.ValueEnd, true
+			}
+		}
+		if !found {
+			return 0, 0, false
+		}
+	}
+	return start, end, true
+}
+
+// get decodes the value at path into target; returns false when it does not exist
+func (d *jsonDocument) get(target interface{}, path ...string) bool {
+	start, end, ok := d.locate(path)
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(d.data[start:end], target) == nil
+}
+
+// has reports whether a value exists at path
+func (d *jsonDocument) has(path ...string) bool {
+	_, _, ok := d.locate(path)
+	return ok
+}
+
+// lineIndent returns the whitespace that starts the line containing offset
+func (d *jsonDocument) lineIndent(offset int) string {
+	lineStart := bytes.LastIndexByte(d.data[:offset], '\n') + 1
+	end := lineStart
+	for end < len(d.data) && (d.data[end] == ' ' || d.data[end] == '\t') {
+		end++
+	}
+	return string(d.data[lineStart:end])
+}
+
+// indentUnit guesses the indentation step of the document (two spaces by default)
+func (d *jsonDocument) indentUnit() string {
+	for _, line := range strings.Split(string(d.data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
+
+// multiline reports whether the document is pretty-printed
+func (d *jsonDocument) multiline() bool {
+	return bytes.IndexByte(bytes.TrimSpace(d.data), '\n') >= 0
+}
+
+// memberStyle returns the separators used by the document: between a key and its value
+// (e.g. ": " or ":") and after the comma between members written on one line (" " or "").
+// They are taken from the first objects that have members, with ": " and " " as defaults
+// (no space after commas when the colon has none)
+func (d *jsonDocument) memberStyle() (string, string) {
+	colon, comma := "", ""
+	var walk func(open int)
+	walk = func(open int) {
+		members, _ := d.members(open)
+		for i, member := range members {
+			if colon == "" {
+				colon = string(d.data[d.valueEnd(member.KeyStart):member.ValueStart])
+			}
+			if comma == "" && i > 0 {
+				between := d.data[members[i-1].ValueEnd:member.KeyStart]
+				if bytes.IndexByte(between, '\n') < 0 {
+					comma = string(between[bytes.IndexByte(between, ',')+1:])
+					if comma == "" {
+						comma = "none"
+					}
+				}
+			}
+			if d.data[member.ValueStart] == '{' && (colon == "" || comma == "") {
+				walk(member.ValueStart)
+			}
+		}
+	}
+	walk(d.skipSpace(0))
+	if colon == "" {
+		colon = ": "
+	}
+	switch {
+	case comma == "none" || (comma == "" && colon == ":"):
+		// A compact document has no space after its commas either
+		comma = ""
+	case comma == "":
+		comma = " "
+	}
+	return colon, comma
+}
+
+// encode formats a value for insertion at the given indentation
+func (d *jsonDocument) encode(value interface{}, indent string) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if d.multiline() {
+		encoder.SetIndent(indent, d.indentUnit())
+	}
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buffer.Bytes(), "\n"), nil
+}
+
+// splice replaces data[start:end] with text
+func (d *jsonDocument) splice(start, end int, text []byte) {
+	data := make([]byte, 0, len(d.data)-(end-start)+len(text))
+	data = append(data, d.data[:start]...)
+	data = append(data, text...)
+	data = append(data, d.data[end:]...)
+	d.data = data
+}
+
+// set writes value at path, creating missing objects along the way
+// An existing value is replaced in place; a new key is appended to its object
+// with the indentation and separator style of its siblings
+func (d *jsonDocument) set(value interface{}, path ...string) error {
+	if len(path) == 0 {
+		return fmt.Errorf("empty JSON path")
+	}
+	if start, end, ok := d.locate(path); ok {
+		encoded, err := d.encode(value, d.lineIndent(start))
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(d.data[start:end], encoded) {
+			d.splice(start, end, encoded)
+		}
+		return nil
+	}
+
+	// Find the deepest existing object, and wrap the value in the missing ones
+	depth := len(path) - 1
+	for depth > 0 && !d.has(path[:depth]...) {
+		depth--
+	}
+	for i := len(path) - 1; i > depth; i-- {
+		value = map[string]interface{}{path[i]: value}
+	}
+	open, _, _ := d.locate(path[:depth])
+	if d.data[open] != '{' {
+		return fmt.Errorf("%s is not an object", strings.Join(path[:depth], "."))
+	}
+	return d.insert(open, path[depth], value)
+}
+
+// insert adds a key at the end of the object starting at open
+func (d *jsonDocument) insert(open int, key string, value interface{}) error {
+	members, close := d.members(open)
+	keyText, _ := d.encode(key, "")
+	multiline := bytes.IndexByte(d.data[open:close], '\n') >= 0 || (len(members) == 0 && d.multiline())
+	separator, leading := d.memberStyle()
+
+	if len(members) == 0 {
+		indent := d.lineIndent(open)
+		if !multiline {
+			encoded, err := d.encode(value, "")
+			if err != nil {
+				return err
+			}
+			d.splice(open+1, close, []byte(fmt.Sprintf("%s%s%s", keyText, separator, encoded)))
+			return nil
+		}
+		memberIndent := indent + d.indentUnit()
+		encoded, err := d.encode(value, memberIndent)
+		if err != nil {
+			return err
+		}
+		d.splice(open+1, close, []byte(fmt.Sprintf("\n%s%s%s%s\n%s", memberIndent, keyText, separator, encoded, indent)))
+		return nil
+	}
+
+	// Copy the layout of the object: what precedes its keys, and its key/value separator
+	first := members[0]
+	separator = string(d.data[d.valueEnd(first.KeyStart):first.ValueStart])
+	memberIndent := ""
+	if multiline {
+		memberIndent = d.lineIndent(first.KeyStart)
+		leading = "\n" + memberIndent
+	} else if len(members) > 1 {
+		between := d.data[first.ValueEnd:members[1].KeyStart]
+		leading = string(between[bytes.IndexByte(between, ',')+1:])
+	}
+	encoded, err := d.encode(value, memberIndent)
+	if err != nil {
+		return err
+	}
+	last := members[len(members)-1]
+	d.splice(last.ValueEnd, last.ValueEnd, []byte(fmt.Sprintf(",%s%s%s%s", leading, keyText, separator, encoded)))
+	return nil
+}
+
+// remove deletes the key at path with its separator; returns false when it does not exist
+func (d *jsonDocument) remove(path ...string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	open, _, ok := d.locate(path[:len(path)-1])
+	if !ok || d.data[open] != '{' {
+		return false
+	}
+	members, close := d.members(open)
+	for i, member := range members {
+		if member.Key != path[len(path)-1] {
+			continue
+		}
+		switch {
+		case len(members) == 1:
+			d.splice(open+1, close, nil)
+		case i < len(members)-1:
+			d.splice(member.KeyStart, members[i+1].KeyStart, nil)
+		default:
+			d.splice(members[i-1].ValueEnd, member.ValueEnd, nil)
+		}
+		return true
+	}
+	return false
+}
+
+// write saves the document, keeping the permissions of an existing file
+func (d *jsonDocument) write() error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(d.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return os.WriteFile(d.path, d.data, mode)
+}
@@ -0,0 +1,235 @@
+package modules
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONDocumentEdits(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		edit func(d *jsonDocument) error
+		want string
+	}{
+		// Replacing a value
+		{
+			name: "set existing value with 2 spaces",
+			doc:  "{\n  \"name\": \"app\",\n  \"version\": \"1.0.0\"\n}\n",
+			edit: func(d *jsonDocument) error { return d.set("2.0.0", "version") },
+			want: "{\n  \"name\": \"app\",\n  \"version\": \"2.0.0\"\n}\n",
+		},
+		{
+			name: "set same value keeps number formatting",
+			doc:  "{\"port\": 3.0e3, \"name\": \"app\"}",
+			edit: func(d *jsonDocument) error { return d.set("app", "name") },
+			want: "{\"port\": 3.0e3, \"name\": \"app\"}",
+		},
+		{
+			name: "set object value indented at its depth",
+			doc:  "{\n    \"a\": {\n        \"b\": 1\n    }\n}\n",
+			edit: func(d *jsonDocument) error { return d.set(map[string]int{"x": 1}, "a", "b") },
+			want: "{\n    \"a\": {\n        \"b\": {\n            \"x\": 1\n        }\n    }\n}\n",
+		},
+
+		// Adding a member
+		{
+			name: "add with 2 spaces",
+			doc:  "{\n  \"name\": \"app\"\n}\n",
+			edit: func(d *jsonDocument) error { return d.set(1, "port") },
+			want: "{\n  \"name\": \"app\",\n  \"port\": 1\n}\n",
+		},
+		{
+			name: "add with 4 spaces",
+			doc:  "{\n    \"name\": \"app\"\n}",
+			edit: func(d *jsonDocument) error { return d.set(1, "port") },
+			want: "{\n    \"name\": \"app\",\n    \"port\": 1\n}",
+		},
+		{
+			name: "add with tabs",
+			doc:  "{\n\t\"name\": \"app\",\n\t\"sys\": {\n\t\t\"a\": 1\n\t}\n}\n",
+			edit: func(d *jsonDocument) error { return d.set(2, "sys", "b") },
+			want: "{\n\t\"name\": \"app\",\n\t\"sys\": {\n\t\t\"a\": 1,\n\t\t\"b\": 2\n\t}\n}\n",
+		},
+		{
+			name: "add to compact document",
+			doc:  `{"name":"app","sys":{"a":1}}`,
+			edit: func(d *jsonDocument) error { return d.set(2, "sys", "b") },
+			want: `{"name":"app","sys":{"a":1,"b":2}}`,
+		},
+		{
+			name: "add to empty object of compact document",
+			doc:  `{"name":"app","deps":{}}`,
+			edit: func(d *jsonDocument) error { return d.set("^1", "deps", "cors") },
+			want: `{"name":"app","deps":{"cors":"^1"}}`,
+		},
+		{
+			name: "add to single-line document with spaces",
+			doc:  `{"name": "app", "deps": {}}`,
+			edit: func(d *jsonDocument) error { return d.set("^1", "deps", "cors") },
+			want: `{"name": "app", "deps": {"cors": "^1"}}`,
+		},
+		{
+			name: "add to single-member compact document",
+			doc:  `{"name":"app"}`,
+			edit: func(d *jsonDocument) error { return d.set(1, "port") },
+			want: `{"name":"app","port":1}`,
+		},
+		{
+			name: "add to empty pretty-printed object",
+			doc:  "{\n  \"a\": 1,\n  \"deps\": {}\n}\n",
+			edit: func(d *jsonDocument) error { return d.set("^1", "deps", "cors") },
+			want: "{\n  \"a\": 1,\n  \"deps\": {\n    \"cors\": \"^1\"\n  }\n}\n",
+		},
+		{
+			name: "add to new document",
+			doc:  "{\n}\n",
+			edit: func(d *jsonDocument) error { return d.set(1, "port") },
+			want: "{\n  \"port\": 1\n}\n",
+		},
+		{
+			name: "create nested path",
+			doc:  "{\n  \"name\": \"app\"\n}\n",
+			edit: func(d *jsonDocument) error { return d.set(3000, "__sys__", "__port__") },
+			want: "{\n  \"name\": \"app\",\n  \"__sys__\": {\n    \"__port__\": 3000\n  }\n}\n",
+		},
+		{
+			name: "create nested path in compact document",
+			doc:  `{"a":{}}`,
+			edit: func(d *jsonDocument) error { return d.set(true, "a", "b", "c") },
+			want: `{"a":{"b":{"c":true}}}`,
+		},
+		{
+			name: "set through a non-object fails",
+			doc:  `{"a": 1}`,
+			edit: func(d *jsonDocument) error {
+				if d.set(2, "a", "b") == nil {
+					t.Error("expected an error")
+				}
+				return nil
+			},
+			want: `{"a": 1}`,
+		},
+
+		// Escaped keys
+		{
+			name: "set escaped key",
+			doc:  "{\"\\u0061\": 1, \"b\\\"c\": 2}",
+			edit: func(d *jsonDocument) error { return d.set(5, "b\"c") },
+			want: "{\"\\u0061\": 1, \"b\\\"c\": 5}",
+		},
+		{
+			name: "find key written with a unicode escape",
+			doc:  "{\"\\u0061\": 1}",
+			edit: func(d *jsonDocument) error { return d.set(2, "a") },
+			want: "{\"\\u0061\": 2}",
+		},
+		{
+			name: "add key that needs escaping",
+			doc:  `{"a": 1}`,
+			edit: func(d *jsonDocument) error { return d.set(2, "x\"y<z>") },
+			want: `{"a": 1, "x\"y<z>": 2}`,
+		},
+		{
+			name: "braces inside strings are ignored",
+			doc:  `{"a": "}{", "b": 1}`,
+			edit: func(d *jsonDocument) error { return d.set(2, "b") },
+			want: `{"a": "}{", "b": 2}`,
+		},
+
+		// Removing a member
+		{
+			name: "remove first member",
+			doc:  "{\n  \"a\": 1,\n  \"b\": 2\n}\n",
+			edit: func(d *jsonDocument) error { d.remove("a"); return nil },
+			want: "{\n  \"b\": 2\n}\n",
+		},
+		{
+			name: "remove middle member",
+			doc:  `{"a":1,"b":2,"c":3}`,
+			edit: func(d *jsonDocument) error { d.remove("b"); return nil },
+			want: `{"a":1,"c":3}`,
+		},
+		{
+			name: "remove last member",
+			doc:  "{\n\t\"a\": 1,\n\t\"b\": {\n\t\t\"c\": 2\n\t}\n}\n",
+			edit: func(d *jsonDocument) error { d.remove("b"); return nil },
+			want: "{\n\t\"a\": 1\n}\n",
+		},
+		{
+			name: "remove only member",
+			doc:  "{\n  \"a\": {\n    \"b\": 1\n  }\n}\n",
+			edit: func(d *jsonDocument) error { d.remove("a", "b"); return nil },
+			want: "{\n  \"a\": {}\n}\n",
+		},
+		{
+			name: "remove missing member",
+			doc:  `{"a": 1}`,
+			edit: func(d *jsonDocument) error {
+				if d.remove("b") || d.remove("a", "b") {
+					t.Error("remove reported a missing key as removed")
+				}
+				return nil
+			},
+			want: `{"a": 1}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := parseJSONDocument("test.json", []byte(test.doc))
+			if test.doc == "{\n}\n" {
+				doc, err = newJSONDocument("test.json"), nil
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := test.edit(doc); err != nil {
+				t.Fatal(err)
+			}
+			if got := string(doc.data); got != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+			if !json.Valid(doc.data) {
+				t.Errorf("result is not valid JSON:\n%s", doc.data)
+			}
+		})
+	}
+}
+
+func TestJSONDocumentLocate(t *testing.T) {
+	doc, err := parseJSONDocument("test.json", []byte(`{"a": {"b": [1, {"c": 2}], "d": "x"}, "e": null}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path []string
+		want string
+		ok   bool
+	}{
+		{nil, `{"a": {"b": [1, {"c": 2}], "d": "x"}, "e": null}`, true},
+		{[]string{"a", "b"}, `[1, {"c": 2}]`, true},
+		{[]string{"a", "d"}, `"x"`, true},
+		{[]string{"e"}, `null`, true},
+		{[]string{"a", "b", "c"}, "", false},
+		{[]string{"missing"}, "", false},
+	}
+	for _, test := range tests {
+		start, end, ok := doc.locate(test.path)
+		if ok != test.ok || (ok && string(doc.data[start:end]) != test.want) {
+			t.Errorf("locate(%q) = %q, %v; want %q, %v", test.path, doc.data[start:end], ok, test.want, test.ok)
+		}
+	}
+
+	var value string
+	if !doc.get(&value, "a", "d") || value != "x" {
+		t.Errorf("get(a.d) = %q", value)
+	}
+}
+
+func TestParseJSONDocumentRejectsNonObjects(t *testing.T) {
+	for _, content := range []string{`[1]`, `"x"`, `{"a": }`, ``} {
+		if _, err := parseJSONDocument("test.json", []byte(content)); err == nil {
+			t.Errorf("%q was accepted", content)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Console is the output layer used by every command
+// It decides once, at startup, whether colors, emoji and animations are used,
+// and filters output in --quiet mode
+type Console struct {
+	mu          sync.Mutex
+	Color       bool // Emit ANSI colors
+	Emoji       bool // Emit emoji and Unicode symbols (otherwise plain ASCII)
+	Quiet       bool // Only print errors, warnings and prompts
+	Interactive bool // Animate spinners, redraw progress and show the logo
+	plain       *strings.Replacer
+}
+
+// console is the process-wide output layer
+var console = &Console{Color: true, Emoji: true, Interactive: true}
+
+// OutputOptions are the user-facing switches of the output layer
+type OutputOptions struct {
+	Color   string // "color" setting: auto, always or never
+	NoColor bool
+	NoEmoji bool
+	Quiet   bool
+}
+
+// configureConsole applies the environment and flags to the output layer
+// Colors follow whether stdout is a terminal, then the "color" setting, then
+// NO_COLOR / FORCE_COLOR / TERM=dumb; --no-color always wins. Animations are only used on an interactive terminal
+func configureConsole(opts OutputOptions) {
+	tty := isTerminal(os.Stdout)
+	dumb := os.Getenv("TERM") == "dumb"
+
+	color := tty && !dumb
+	switch opts.Color {
+	case "always":
+		color = !dumb
+	case "never":
+		color = false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		color = false
+	}
+	if force := os.Getenv("FORCE_COLOR"); force != "" && force != "0" && force != "false" {
+		color = true
+	}
+	if opts.NoColor {
+		color = false
+	}
+
+	console.Color = color
+	console.Emoji = !opts.NoEmoji
+	console.Quiet = opts.Quiet
+	console.Interactive = tty && !dumb && !opts.Quiet
+
+	if !console.Color {
+		disableColors()
+	}
+	if !console.Emoji {
+		console.plain = plainReplacer()
+	}
+}
+
+// disableColors blanks every color code so formatted output stays plain
+func disableColors() {
+	ColorReset, ColorRed, ColorGreen, ColorYellow, ColorBlue = "", "", "", "", ""
+	ColorMagenta, ColorCyan, ColorWhite, ColorBold, ColorDim = "", "", "", "", ""
+}
+
+// plainReplacer maps emoji and Unicode symbols to ASCII for --no-emoji
+// Emoji used as line decorations are dropped together with their trailing space
+func plainReplacer() *strings.Replacer {
+	pairs := []string{}
+	for _, emoji := range []string{"🚀", "📦", "📥", "🔧", "📋", "🎉", "🔥", "🔐", "📁", "🌐", "🗑️", "✨"} {
+		pairs = append(pairs, emoji+"  ", "", emoji+" ", "", emoji, "")
+	}
+	pairs = append(pairs,
+		"❌", "x", "✅", "+", "✓", "+", "✗", "x", "⚠", "!", "⚡", "*", "⚙", "*", "→", "->", "·", ".",
+		"├─", "|-", "└─", "`-", "┌─", ",-", "│", "|", "─", "-", "┐", "+", "┘", "+",
+		"╔", "+", "╗", "+", "╚", "+", "╝", "+", "═", "=", "║", "|",
+		"█", "#", "░", "-",
+		"❯", ">", "◉", "(*)", "◯", "( )", "✔", "+", "↑/↓", "up/down",
+	)
+	return strings.NewReplacer(pairs...)
+}
+
+// render applies the plain-mode mapping to a piece of output
+func (c *Console) render(text string) string {
+	if c.plain != nil {
+		return c.plain.Replace(text)
+	}
+	return text
+}
+
+// keepInQuiet reports whether a line is still printed in quiet mode
+// Errors and warnings use ❌, ✗ or ⚠ throughout the CLI
+func keepInQuiet(text string) bool {
+	return strings.Contains(text, "❌") || strings.Contains(text, "✗") || strings.Contains(text, "⚠")
+}
+
+// write sends already formatted text to stdout through the output layer
+// Text written with always set (prompts, command results) is never filtered by --quiet
+func (c *Console) write(text string, always bool) {
+	if c.Quiet && !always && !keepInQuiet(text) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	os.Stdout.WriteString(c.render(text))
+}
+
+// printf formats and prints a message through the output layer
+func printf(format string, a ...interface{}) {
+	console.write(fmt.Sprintf(format, a...), false)
+}
+
+// printLine prints its arguments followed by a newline through the output layer
+func printLine(a ...interface{}) {
+	console.write(fmt.Sprintln(a...), false)
+}
+
+// promptf prints an interactive prompt; prompts are shown even in quiet mode
+func promptf(format string, a ...interface{}) {
+	console.write(fmt.Sprintf(format, a...), true)
+}
+
+// resultf prints the result of a command; results are shown even in quiet mode
+func resultf(format string, a ...interface{}) {
+	console.write(fmt.Sprintf(format, a...), true)
+}
+
+// spinnerFrameSet returns the spinner animation frames for the current mode
+func spinnerFrameSet() []string {
+	if console.Emoji {
+		return spinnerFrames
+	}
+	return []string{"|", "/", "-", "\\"}
+}
+
+// printLogo prints the XyPriss banner on interactive terminals only
+func printLogo() {
+	if !console.Interactive {
+		return
+	}
+	printLine(ColorCyan + XyPrissLogo + ColorReset + ColorBlue + XyPrissTagline + ColorReset)
+}
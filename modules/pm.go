@@ -0,0 +1,151 @@
+package modules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// InstallStampFile records the lockfile hash of the last install, inside node_modules
+const InstallStampFile = ".xypcli-lock-hash"
+
+// lockfiles maps the lockfile of each package manager, in detection order
+var lockfiles = []struct {
+	name    string
+	manager string
+}{
+	{"bun.lock", "bun"},
+	{"bun.lockb", "bun"},
+	{"pnpm-lock.yaml", "pnpm"},
+	{"yarn.lock", "yarn"},
+	{"package-lock.json", "npm"},
+}
+
+// packageManagerHint returns how to install a package manager
+func packageManagerHint(manager string) string {
+	switch manager {
+	case "bun":
+		return "Install Bun from https://bun.sh, or pass --mode n to use npm"
+	case "pnpm", "yarn":
+		return "Run 'corepack enable' (Node.js 16.10+) or install " + manager + " globally"
+	}
+	return "Install Node.js from https://nodejs.org"
+}
+
+// findLockfile returns the name of the first lockfile found in dir, and its package manager
+func findLockfile(dir string) (string, string) {
+	for _, lockfile := range lockfiles {
+		if fileExists(filepath.Join(dir, lockfile.name)) {
+			return lockfile.name, lockfile.manager
+		}
+	}
+	return "", ""
+}
+
+// detectPackageManager chooses the package manager of the project in dir
+// Precedence: --mode, the "packageManager" field of package.json, the lockfile,
+// the configured mode and finally bun when available (npm otherwise).
+// Returns the manager and where it was found
+func (c *CLITool) detectPackageManager(dir string, pkg packageJSON, mode string) (string, string, error) {
+	manager, source := "", ""
+	switch {
+	case mode == "b":
+		manager, source = "bun", "--mode b"
+	case mode == "n":
+		manager, source = "npm", "--mode n"
+	case pkg.PackageManager != "":
+		// e.g. "pnpm@9.1.0" or "yarn@4.2.2+sha512..."
+		manager, source = strings.SplitN(pkg.PackageManager, "@", 2)[0], "package.json packageManager"
+	default:
+		if lockfile, lockManager := findLockfile(dir); lockfile != "" {
+			manager, source = lockManager, lockfile
+		}
+	}
+
+	if manager == "" {
+		switch c.settings.String("mode") {
+		case "b":
+			manager, source = "bun", "mode setting"
+		case "n":
+			manager, source = "npm", "mode setting"
+		default:
+			manager, source = "npm", "default"
+			if _, err := exec.LookPath("bun"); err == nil {
+				manager = "bun"
+			}
+		}
+	}
+
+	switch manager {
+	case "bun", "npm", "pnpm", "yarn":
+	default:
+		return "", "", environmentError("unsupported package manager '%s' (from %s)", manager, source).
+			WithHint("Supported package managers are bun, npm, pnpm and yarn")
+	}
+	if _, err := exec.LookPath(manager); err != nil {
+		return "", "", environmentError("%s is not installed (required by %s)", manager, source).
+			WithHint("%s", packageManagerHint(manager))
+	}
+	return manager, source, nil
+}
+
+// lockfileHash returns the SHA-256 of the project's lockfile, or "" without a lockfile
+func lockfileHash(dir string) string {
+	lockfile, _ := findLockfile(dir)
+	if lockfile == "" {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(dir, lockfile))
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// staleDependencies reports why node_modules must be (re)installed, or "" when it is up to date
+// The lockfile hash is compared with the stamp written by the last install. A node_modules
+// without a stamp (installed by hand, or before xypcli wrote stamps) is trusted and stamped,
+// so that later lockfile changes are still detected
+func staleDependencies(dir string) string {
+	if info, err := os.Stat(filepath.Join(dir, "node_modules")); err != nil || !info.IsDir() {
+		return "node_modules is missing"
+	}
+	hash := lockfileHash(dir)
+	if hash == "" {
+		return ""
+	}
+	stamp, err := os.ReadFile(filepath.Join(dir, "node_modules", InstallStampFile))
+	if os.IsNotExist(err) {
+		writeInstallStamp(dir)
+		return ""
+	}
+	if err != nil {
+		return "the install stamp cannot be read"
+	}
+	if strings.TrimSpace(string(stamp)) != hash {
+		return "the lockfile changed since the last install"
+	}
+	return ""
+}
+
+// writeInstallStamp records the current lockfile hash after a successful install
+func writeInstallStamp(dir string) {
+	hash := lockfileHash(dir)
+	if hash == "" {
+		return
+	}
+	os.WriteFile(filepath.Join(dir, "node_modules", InstallStampFile), []byte(hash+"\n"), 0644)
+}
+
+// installCommand returns the command installing every dependency of a project
+func installCommand(manager, registry string) []string {
+	args := []string{manager, "install"}
+	if registry != "" {
+		args = append(args, "--registry", registry)
+	}
+	return args
+}
@@ -0,0 +1,223 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// ProjectPolicyPath is the location of the optional per-project install policy file,
+// relative to the project root
+const ProjectPolicyPath = ".xypcli/policies.json"
+
+// PackagePolicy describes how a single package has to be installed
+// Packages without a policy are installed with the selected package manager and no extra flags
+type PackagePolicy struct {
+	Manager             string   `json:"manager,omitempty"`             // Required package manager: "npm" or "bun" (empty = any)
+	AllowScripts        *bool    `json:"allowScripts,omitempty"`        // Whether lifecycle scripts may run (nil = manager default)
+	Flags               []string `json:"flags,omitempty"`               // Extra flags such as --legacy-peer-deps
+	TrustedDependencies []string `json:"trustedDependencies,omitempty"` // Packages bun must trust to run their scripts
+	Reason              string   `json:"reason,omitempty"`              // Human readable explanation shown in output
+}
+
+// InstallPolicies holds the package policy table used by every install path
+type InstallPolicies struct {
+	Packages map[string]PackagePolicy `json:"packages"`
+}
+
+// builtinPolicies are the policies shipped with the CLI
+// Project policies are merged on top of these, package by package
+func builtinPolicies() InstallPolicies {
+	allow := true
+	return InstallPolicies{
+		Packages: map[string]PackagePolicy{
+			"nquickdev": {
+				Manager:             "npm",
+				AllowScripts:        &allow,
+				TrustedDependencies: []string{"nquickdev"},
+				Reason:              "postinstall script required (ignored by bun)",
+			},
+		},
+	}
+}
+
+// LoadInstallPolicies returns the built-in policies merged with the project's
+// .xypcli/policies.json, if present. A broken project file is reported and ignored
+func LoadInstallPolicies(projectDir string) InstallPolicies {
+	policies := builtinPolicies()
+
+	data, err := ioutil.ReadFile(filepath.Join(projectDir, ProjectPolicyPath))
+	if err != nil {
+		return policies
+	}
+
+	var project InstallPolicies
+	if err := json.Unmarshal(data, &project); err != nil {
+		printf("  %s⚠ Ignoring %s: %v%s\n", ColorYellow, ProjectPolicyPath, err, ColorReset)
+		return policies
+	}
+
+	for name, policy := range project.Packages {
+		policies.Packages[name] = policy
+	}
+	printf("  %s→ Install policies: %s (%d package(s))%s\n", ColorDim, ProjectPolicyPath, len(project.Packages), ColorReset)
+	return policies
+}
+
+// Lookup returns the policy for a package, accepting names with a version or tag
+// suffix such as "nquickdev@1.0.3" or "@scope/pkg@latest"
+func (p InstallPolicies) Lookup(packageName string) (PackagePolicy, bool) {
+	policy, ok := p.Packages[barePackageName(packageName)]
+	return policy, ok
+}
+
+// TrustedFor collects the bun trusted dependencies required by the given packages
+func (p InstallPolicies) TrustedFor(packages []string) []string {
+	seen := make(map[string]bool)
+	trusted := []string{}
+	for _, pkg := range packages {
+		policy, ok := p.Lookup(pkg)
+		if !ok {
+			continue
+		}
+		for _, dep := range policy.TrustedDependencies {
+			if !seen[dep] {
+				seen[dep] = true
+				trusted = append(trusted, dep)
+			}
+		}
+	}
+	sort.Strings(trusted)
+	return trusted
+}
+
+// policyFor returns the policy for a package, or an empty policy
+func policyFor(policies InstallPolicies, packageName string) PackagePolicy {
+	policy, _ := policies.Lookup(packageName)
+	return policy
+}
+
+// barePackageName strips a version or tag suffix from a package spec
+func barePackageName(spec string) string {
+	start := 0
+	if len(spec) > 0 && spec[0] == '@' {
+		start = 1
+	}
+	for i := start; i < len(spec); i++ {
+		if spec[i] == '@' {
+			return spec[:i]
+		}
+	}
+	return spec
+}
+
+// resolveInstallManager returns the package manager to use for a package given
+// the manager selected for the whole run
+func resolveInstallManager(policy PackagePolicy, useBun bool) string {
+	switch policy.Manager {
+	case "npm":
+		return "npm"
+	case "bun":
+		if _, err := exec.LookPath("bun"); err == nil {
+			return "bun"
+		}
+		return "npm"
+	}
+	if useBun {
+		return "bun"
+	}
+	return "npm"
+}
+
+// buildInstallCommand prepares the install command for a package according to its policy
+// Returns the command and the package manager it runs
+func buildInstallCommand(policies InstallPolicies, projectDir, packageName string, isDev, useBun bool, registry string) (*exec.Cmd, string) {
+	policy := policyFor(policies, packageName)
+	manager := resolveInstallManager(policy, useBun)
+
+	var args []string
+	if manager == "bun" {
+		args = []string{"add"}
+		if isDev {
+			args = append(args, "-d")
+		}
+	} else {
+		args = []string{"install"}
+		if isDev {
+			args = append(args, "--save-dev")
+		}
+	}
+
+	if policy.AllowScripts != nil && !*policy.AllowScripts {
+		args = append(args, "--ignore-scripts")
+	}
+	if registry != "" {
+		args = append(args, "--registry", registry)
+	}
+	args = append(args, policy.Flags...)
+	args = append(args, packageName)
+
+	cmd := exec.Command(manager, args...)
+	cmd.Dir = projectDir
+	return cmd, manager
+}
+
+// applyTrustedDependencies adds the trusted dependencies required by the given packages
+// to the project's package.json so bun runs their lifecycle scripts.
+// It must run before installs start, as bun rewrites package.json while adding packages
+func (c *CLITool) applyTrustedDependencies(projectDir string, policies InstallPolicies, packages []string) {
+	trusted := policies.TrustedFor(packages)
+	if len(trusted) == 0 {
+		return
+	}
+
+	doc, err := readJSONDocument(filepath.Join(projectDir, "package.json"))
+	if err != nil {
+		return
+	}
+
+	existing := map[string]bool{}
+	current := []interface{}{}
+	doc.get(&current, "trustedDependencies")
+	for _, item := range current {
+		if name, ok := item.(string); ok {
+			existing[name] = true
+		}
+	}
+
+	// Only the trustedDependencies array is rewritten; the rest of package.json is kept as is
+	list := current
+	for _, name := range trusted {
+		if !existing[name] {
+			list = append(list, name)
+			existing[name] = true
+		}
+	}
+	if len(list) == len(current) {
+		return
+	}
+	if err := doc.set(list, "trustedDependencies"); err != nil {
+		return
+	}
+	if err := doc.write(); err == nil {
+		printf("  %s→ Trusted dependencies: %v%s\n", ColorDim, trusted, ColorReset)
+	}
+}
+
+// policyNote returns a short annotation for packages whose policy changes how they install
+func policyNote(policies InstallPolicies, packageName string, useBun bool) string {
+	policy, ok := policies.Lookup(packageName)
+	if !ok {
+		return ""
+	}
+	if manager := resolveInstallManager(policy, useBun); useBun && manager != "bun" {
+		if policy.Reason != "" {
+			return fmt.Sprintf("via %s: %s", manager, policy.Reason)
+		}
+		return "via " + manager
+	}
+	return ""
+}
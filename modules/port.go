@@ -0,0 +1,186 @@
+package modules
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// XyPrissConfigFile is the framework configuration of a project
+const XyPrissConfigFile = "xypriss.config.json"
+
+// maxPortScan is how many ports above the configured one "--port auto" tries
+const maxPortScan = 100
+
+// portInUse reports whether a TCP port cannot be bound on this machine
+func portInUse(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return true
+	}
+	listener.Close()
+	return false
+}
+
+// nextFreePort returns the first free port from start upwards
+func nextFreePort(start int) (int, error) {
+	for port := start; port < start+maxPortScan && port <= 65535; port++ {
+		if !portInUse(port) {
+			return port, nil
+		}
+	}
+	return 0, environmentError("no free port found between %d and %d", start, minInt(start+maxPortScan-1, 65535))
+}
+
+// ssProcess matches the process column of "ss -p": users:(("node",pid=1234,fd=20))
+var ssProcess = regexp.MustCompile(`\("([^"]+)",pid=(\d+)`)
+
+// portHolder describes the process listening on a port (e.g. "node (pid 1234)"), or "" if unknown
+// It relies on lsof or ss on Unix and netstat on Windows; missing tools just give no answer
+func portHolder(port int) string {
+	if runtime.GOOS == "windows" {
+		output, err := exec.Command("netstat", "-ano", "-p", "tcp").Output()
+		if err != nil {
+			return ""
+		}
+		suffix := fmt.Sprintf(":%d", port)
+		for _, line := range strings.Split(string(output), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 5 && strings.HasSuffix(fields[1], suffix) && fields[3] == "LISTENING" {
+				return "pid " + fields[4]
+			}
+		}
+		return ""
+	}
+
+	if output, err := exec.Command("lsof", "-nP", fmt.Sprintf("-iTCP:%d", port), "-sTCP:LISTEN", "-Fpc").Output(); err == nil {
+		pid, name := "", ""
+		for _, line := range strings.Split(string(output), "\n") {
+			if strings.HasPrefix(line, "p") && pid == "" {
+				pid = line[1:]
+			} else if strings.HasPrefix(line, "c") && name == "" {
+				name = line[1:]
+			}
+		}
+		if pid != "" {
+			return fmt.Sprintf("%s (pid %s)", orDefault(name, "unknown"), pid)
+		}
+	}
+	if output, err := exec.Command("ss", "-Hltnp", fmt.Sprintf("sport = :%d", port)).Output(); err == nil {
+		if match := ssProcess.FindStringSubmatch(string(output)); match != nil {
+			return fmt.Sprintf("%s (pid %s)", match[1], match[2])
+		}
+	}
+	return ""
+}
+
+// readSysPort returns __sys__.__port__ (or __sys__.__PORT__) from the xypriss.config.json in dir, or 0
+func readSysPort(dir string) int {
+	sys := readSysConfig(dir)
+	if sys.Port != 0 {
+		return sys.Port
+	}
+	return sys.PortUpper
+}
+
+// siblingProjectsOnPort returns the XyPriss projects directly inside dir configured with port
+func siblingProjectsOnPort(dir string, port int, exclude string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	projects := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == exclude {
+			continue
+		}
+		if readSysPort(filepath.Join(dir, entry.Name())) == port {
+			projects = append(projects, entry.Name())
+		}
+	}
+	return projects
+}
+
+// portConflicts describes why a port is a poor choice for a new project in dir, if it is
+func portConflicts(dir, projectName string, port int) []string {
+	conflicts := []string{}
+	if portInUse(port) {
+		if holder := portHolder(port); holder != "" {
+			conflicts = append(conflicts, fmt.Sprintf("Port %d is in use by %s", port, holder))
+		} else {
+			conflicts = append(conflicts, fmt.Sprintf("Port %d is in use", port))
+		}
+	}
+	if projects := siblingProjectsOnPort(dir, port, projectName); len(projects) > 0 {
+		conflicts = append(conflicts, fmt.Sprintf("Port %d is already used by the XyPriss project(s) %s", port, strings.Join(projects, ", ")))
+	}
+	return conflicts
+}
+
+// warnPortConflicts prints a warning for each conflict of the port chosen at init
+func warnPortConflicts(projectName string, port int) {
+	conflicts := portConflicts(".", projectName, port)
+	for _, conflict := range conflicts {
+		printf("%s⚠ %s%s\n", ColorYellow, conflict, ColorReset)
+	}
+	if len(conflicts) > 0 {
+		if free, err := nextFreePort(port + 1); err == nil {
+			printf("  %s→ Port %d is free (use --port %d)%s\n", ColorDim, free, free, ColorReset)
+		}
+	}
+}
+
+// resolveStartPort checks the port of the project in dir before the server starts
+// requested is the --port value: "" (configured port), a number or "auto" (next free port).
+// Returns the port and whether it must be passed to the server as an override
+func resolveStartPort(dir, requested string) (int, bool, error) {
+	configured := readSysPort(dir)
+
+	if requested != "" && requested != "auto" {
+		value, err := validatePort(requested)
+		if err != nil {
+			return 0, false, usageError("invalid value for --port: %v", err)
+		}
+		port, _ := strconv.Atoi(value)
+		return port, true, checkPortFree(port)
+	}
+
+	if configured == 0 {
+		if requested == "auto" {
+			return 0, false, environmentError("--port auto needs __sys__.__port__ in %s", XyPrissConfigFile).
+				WithHint("Pass a port number instead, e.g. 'xypcli start --port 3000'")
+		}
+		return 0, false, nil
+	}
+
+	if requested == "auto" {
+		port, err := nextFreePort(configured)
+		if err != nil {
+			return 0, false, err
+		}
+		if port != configured {
+			printf("  %s⚠ Port %d is in use, using port %d%s\n", ColorYellow, configured, port, ColorReset)
+		}
+		return port, port != configured, nil
+	}
+	return configured, false, checkPortFree(configured)
+}
+
+// checkPortFree returns an error naming the process that holds a port
+func checkPortFree(port int) error {
+	if !portInUse(port) {
+		return nil
+	}
+	holder := portHolder(port)
+	if holder == "" {
+		holder = "another process"
+	}
+	return environmentError("port %d is already in use by %s", port, holder).
+		WithHint("Stop that process, or run 'xypcli start --port auto' to use the next free port")
+}
@@ -0,0 +1,87 @@
+//go:build !windows
+
+package modules
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// setProcessGroup starts the command in its own process group, so that the
+// supervisor (not the terminal) decides when the server and its children get a signal
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalProcessGroup sends a signal to every process of the command's group
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	number, ok := sig.(syscall.Signal)
+	if !ok {
+		number = syscall.SIGTERM
+	}
+	return syscall.Kill(-cmd.Process.Pid, number)
+}
+
+// killProcessGroup forcibly stops every process of the command's group
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// detachProcess starts the command in a new session, so closing the terminal does not stop it
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// processAlive reports whether a process exists
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// terminateProcess asks a process to stop (SIGTERM)
+func terminateProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
+
+// killProcess forcibly stops a process
+func killProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGKILL)
+}
+
+// processTreeMemory returns the resident memory of a process and its descendants in bytes (0 if unknown)
+func processTreeMemory(pid int) int64 {
+	output, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,rss=").Output()
+	if err != nil {
+		return 0
+	}
+	children := map[int][]int{}
+	rss := map[int]int64{}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		child, _ := strconv.Atoi(fields[0])
+		parent, _ := strconv.Atoi(fields[1])
+		kilobytes, _ := strconv.ParseInt(fields[2], 10, 64)
+		children[parent] = append(children[parent], child)
+		rss[child] = kilobytes
+	}
+
+	total := int64(0)
+	pending := []int{pid}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = append(pending[1:], children[current]...)
+		total += rss[current]
+	}
+	return total * 1024
+}
+
+// shellCommand runs a command line through the shell
+func shellCommand(line string) []string {
+	return []string{"sh", "-c", line}
+}
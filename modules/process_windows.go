@@ -0,0 +1,60 @@
+//go:build windows
+
+package modules
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a new process group, so that Ctrl+C
+// in the console reaches the supervisor only
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// signalProcessGroup asks the process tree of the command to stop
+// Windows has no POSIX signals: taskkill without /F requests a close
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	return exec.Command("taskkill", "/T", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
+
+// killProcessGroup forcibly stops the process tree of the command
+func killProcessGroup(cmd *exec.Cmd) error {
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
+
+// detachProcess starts the command without a console, so closing the terminal does not stop it
+func detachProcess(cmd *exec.Cmd) {
+	const detachedProcess = 0x00000008
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess}
+}
+
+// processAlive reports whether a process exists
+func processAlive(pid int) bool {
+	output, err := exec.Command("tasklist", "/FI", "PID eq "+strconv.Itoa(pid), "/NH").Output()
+	return err == nil && strings.Contains(string(output), " "+strconv.Itoa(pid)+" ")
+}
+
+// terminateProcess asks a process tree to stop
+func terminateProcess(pid int) error {
+	return exec.Command("taskkill", "/T", "/PID", strconv.Itoa(pid)).Run()
+}
+
+// killProcess forcibly stops a process tree
+func killProcess(pid int) error {
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(pid)).Run()
+}
+
+// processTreeMemory is not measured on Windows
+func processTreeMemory(pid int) int64 {
+	return 0
+}
+
+// shellCommand runs a command line through cmd.exe
+func shellCommand(line string) []string {
+	return []string{"cmd", "/C", line}
+}
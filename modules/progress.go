@@ -0,0 +1,443 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are the frames used by every spinner of the CLI
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// isTerminal reports whether the given file is attached to an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// taskState is the lifecycle state of a single progress task
+type taskState int
+
+const (
+	taskQueued taskState = iota
+	taskRunning
+	taskSucceeded
+	taskFailed
+	taskSkipped
+)
+
+// progressTask is one line of the progress tree (typically a package install)
+type progressTask struct {
+	name    string
+	group   int
+	state   taskState
+	started time.Time
+	elapsed time.Duration
+	note    string   // Annotation shown next to the name (e.g. install policy)
+	detail  string   // Short result detail shown on success
+	errors  []string // Error lines shown under a failed task
+}
+
+// progressGroup is a titled branch of the progress tree
+type progressGroup struct {
+	title string
+	tasks []int
+}
+
+// progressEvent is a state change sent to the renderer goroutine
+type progressEvent struct {
+	id     int
+	state  taskState
+	detail string
+	errors []string
+}
+
+// ProgressRenderer owns the terminal while parallel work is running
+// Workers report state changes through Start/Succeed/Fail, and a single goroutine
+// redraws a stable multi-line tree (TTY) or appends plain lines (non-TTY)
+type ProgressRenderer struct {
+	mu       sync.Mutex
+	groups   []progressGroup
+	tasks    []progressTask
+	events   chan progressEvent
+	done     chan struct{}
+	started  time.Time
+	live     bool // Redraw in place instead of appending lines
+	drawn    int  // Number of lines drawn by the last live frame
+	frame    int
+	finished int
+}
+
+// NewProgressRenderer creates a renderer; live redrawing is used only on interactive terminals
+func NewProgressRenderer() *ProgressRenderer {
+	return &ProgressRenderer{
+		events: make(chan progressEvent, 64),
+		done:   make(chan struct{}),
+		live:   console.Interactive,
+	}
+}
+
+// AddGroup registers a new branch of the tree and returns its index
+// Groups and tasks must be registered before Run is called
+func (p *ProgressRenderer) AddGroup(title string) int {
+	p.groups = append(p.groups, progressGroup{title: title})
+	return len(p.groups) - 1
+}
+
+// AddTask registers a task in a group and returns its id
+func (p *ProgressRenderer) AddTask(group int, name, note string) int {
+	p.tasks = append(p.tasks, progressTask{name: name, group: group, note: note})
+	id := len(p.tasks) - 1
+	p.groups[group].tasks = append(p.groups[group].tasks, id)
+	return id
+}
+
+// Run starts the renderer goroutine
+func (p *ProgressRenderer) Run() {
+	p.started = time.Now()
+	go p.loop()
+}
+
+// Start marks a task as running
+func (p *ProgressRenderer) Start(id int) {
+	p.events <- progressEvent{id: id, state: taskRunning}
+}
+
+// Succeed marks a task as completed successfully with an optional detail
+func (p *ProgressRenderer) Succeed(id int, detail string) {
+	p.events <- progressEvent{id: id, state: taskSucceeded, detail: detail}
+}
+
+// Fail marks a task as failed with the error lines to display under it
+func (p *ProgressRenderer) Fail(id int, errors []string) {
+	p.events <- progressEvent{id: id, state: taskFailed, errors: errors}
+}
+
+// Skip marks a task that will not run (e.g. after a strict-mode failure)
+func (p *ProgressRenderer) Skip(id int) {
+	p.events <- progressEvent{id: id, state: taskSkipped}
+}
+
+// Stop draws the final frame and waits for the renderer goroutine to exit
+// Nothing else may write to stdout between Run and Stop
+func (p *ProgressRenderer) Stop() {
+	close(p.events)
+	<-p.done
+}
+
+// loop is the renderer goroutine: the only writer to stdout while running
+func (p *ProgressRenderer) loop() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(80 * time.Millisecond)
+	defer ticker.Stop()
+
+	if !p.live {
+		p.printHeaders()
+	}
+
+	for {
+		select {
+		case ev, ok := <-p.events:
+			if !ok {
+				if p.live {
+					p.redraw(true)
+				} else {
+					p.printFooter()
+				}
+				return
+			}
+			p.apply(ev)
+			if p.live {
+				p.redraw(false)
+			} else {
+				p.printEvent(ev)
+			}
+		case <-ticker.C:
+			if p.live {
+				p.frame++
+				p.redraw(false)
+			}
+		}
+	}
+}
+
+// apply records a state change
+func (p *ProgressRenderer) apply(ev progressEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	task := &p.tasks[ev.id]
+	switch ev.state {
+	case taskRunning:
+		task.started = time.Now()
+	case taskSucceeded, taskFailed, taskSkipped:
+		if !task.started.IsZero() {
+			task.elapsed = time.Since(task.started)
+		}
+		task.detail = ev.detail
+		task.errors = ev.errors
+		p.finished++
+	}
+	task.state = ev.state
+}
+
+// redraw moves the cursor back over the previous frame and draws the whole tree again
+// A frame taller than the terminal cannot be redrawn in place: finished rows are collapsed
+// first, then the renderer falls back to append-only output. The final frame is always complete
+func (p *ProgressRenderer) redraw(final bool) {
+	height := terminalHeight(os.Stdout)
+	fits := func(lines []string) bool { return final || height <= 0 || len(lines) < height }
+
+	p.mu.Lock()
+	lines := p.frameLines(false)
+	if !fits(lines) {
+		lines = p.frameLines(true)
+	}
+	p.mu.Unlock()
+	if !fits(lines) {
+		p.appendFromNow()
+		return
+	}
+
+	var b strings.Builder
+	if p.drawn > 0 {
+		fmt.Fprintf(&b, "\033[%dA", p.drawn)
+	}
+	for _, line := range lines {
+		b.WriteString("\r\033[K")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	// Clear leftovers if the frame got shorter
+	for i := len(lines); i < p.drawn; i++ {
+		b.WriteString("\r\033[K\n")
+	}
+	if extra := p.drawn - len(lines); extra > 0 {
+		fmt.Fprintf(&b, "\033[%dA", extra)
+	}
+	console.write(b.String(), false)
+	p.drawn = len(lines)
+}
+
+// appendFromNow erases the live frame and switches to append-only output,
+// starting with a line for every task that is no longer queued
+func (p *ProgressRenderer) appendFromNow() {
+	if p.drawn > 0 {
+		console.write(fmt.Sprintf("\033[%dA\r\033[J", p.drawn), false)
+	}
+	p.live, p.drawn = false, 0
+	p.printHeaders()
+
+	p.mu.Lock()
+	tasks := append([]progressTask{}, p.tasks...)
+	p.mu.Unlock()
+	for id, task := range tasks {
+		if task.state != taskQueued {
+			p.printEvent(progressEvent{id: id, state: task.state})
+		}
+	}
+}
+
+// frameLines renders the current state as lines (caller holds the lock)
+// With collapse, the finished and the queued tasks of each group are each shown as one line
+func (p *ProgressRenderer) frameLines(collapse bool) []string {
+	lines := []string{}
+	width := 0
+	for _, task := range p.tasks {
+		if len(task.name) > width {
+			width = len(task.name)
+		}
+	}
+
+	for gi, group := range p.groups {
+		branch, indent := "├─", "│  "
+		if gi == len(p.groups)-1 {
+			branch, indent = "└─", "   "
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %s (%d)%s", ColorDim, branch, group.title, len(group.tasks), ColorReset))
+
+		// Each row is a task line followed by its error lines
+		rows := [][]string{}
+		done, queued := 0, 0
+		for _, id := range group.tasks {
+			task := p.tasks[id]
+			switch {
+			case collapse && (task.state == taskSucceeded || task.state == taskSkipped):
+				done++
+			case collapse && task.state == taskQueued:
+				queued++
+			default:
+				rows = append(rows, append([]string{p.taskLine(task, width)}, task.errors...))
+			}
+		}
+		if done > 0 {
+			summary := fmt.Sprintf("%s✓%s %s%d done%s", ColorGreen, ColorReset, ColorDim, done, ColorReset)
+			rows = append([][]string{{summary}}, rows...)
+		}
+		if queued > 0 {
+			rows = append(rows, []string{fmt.Sprintf("%s· %d queued%s", ColorDim, queued, ColorReset)})
+		}
+
+		for ri, row := range rows {
+			taskBranch, taskIndent := "├─", "│  "
+			if ri == len(rows)-1 {
+				taskBranch, taskIndent = "└─", "   "
+			}
+			lines = append(lines, fmt.Sprintf("%s%s%s%s %s", ColorDim, indent, taskBranch, ColorReset, row[0]))
+			for _, errLine := range row[1:] {
+				lines = append(lines, fmt.Sprintf("%s%s%s %s→ %s%s", ColorDim, indent, taskIndent, ColorYellow, errLine, ColorReset))
+			}
+		}
+	}
+
+	lines = append(lines, "", p.progressBar())
+	return lines
+}
+
+// taskLine renders the state icon, name, elapsed time and detail of a task
+func (p *ProgressRenderer) taskLine(task progressTask, width int) string {
+	name := task.name + strings.Repeat(" ", width-len(task.name))
+	note := ""
+	if task.note != "" {
+		note = fmt.Sprintf(" %s(%s)%s", ColorDim, task.note, ColorReset)
+	}
+
+	switch task.state {
+	case taskRunning:
+		frames := spinnerFrameSet()
+		spin := frames[p.frame%len(frames)]
+		return fmt.Sprintf("%s%s%s %s  %s%s%s%s", ColorCyan, spin, ColorReset, name, ColorDim, formatElapsed(time.Since(task.started)), ColorReset, note)
+	case taskSucceeded:
+		detail := ""
+		if task.detail != "" {
+			detail = "  " + task.detail
+		}
+		return fmt.Sprintf("%s✓%s %s  %s%s%s%s%s", ColorGreen, ColorReset, name, ColorDim, formatElapsed(task.elapsed), detail, ColorReset, note)
+	case taskFailed:
+		return fmt.Sprintf("%s✗%s %s  %s%s (failed)%s%s", ColorRed, ColorReset, name, ColorDim, formatElapsed(task.elapsed), ColorReset, note)
+	case taskSkipped:
+		return fmt.Sprintf("%s- %s  skipped%s", ColorDim, name, ColorReset)
+	default:
+		return fmt.Sprintf("%s·%s %s  %squeued%s%s", ColorDim, ColorReset, name, ColorDim, ColorReset, note)
+	}
+}
+
+// progressBar renders the overall completion bar
+func (p *ProgressRenderer) progressBar() string {
+	const barWidth = 24
+	total := len(p.tasks)
+	filled := 0
+	if total > 0 {
+		filled = p.finished * barWidth / total
+	}
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+	return fmt.Sprintf("%s%s%s %d/%d %s%s%s", ColorCyan, bar, ColorReset, p.finished, total, ColorDim, formatElapsed(time.Since(p.started)), ColorReset)
+}
+
+// printHeaders prints the group titles once in append-only mode
+func (p *ProgressRenderer) printHeaders() {
+	for gi, group := range p.groups {
+		branch := "├─"
+		if gi == len(p.groups)-1 {
+			branch = "└─"
+		}
+		printf("%s%s %s (%d)%s\n", ColorDim, branch, group.title, len(group.tasks), ColorReset)
+	}
+}
+
+// printEvent appends a plain line for a state change in append-only mode
+func (p *ProgressRenderer) printEvent(ev progressEvent) {
+	p.mu.Lock()
+	task := p.tasks[ev.id]
+	p.mu.Unlock()
+
+	progress := fmt.Sprintf("[%d/%d]", ev.id+1, len(p.tasks))
+	note := ""
+	if task.note != "" {
+		note = fmt.Sprintf(" %s(%s)%s", ColorDim, task.note, ColorReset)
+	}
+
+	switch ev.state {
+	case taskRunning:
+		printf("   %s├─ %s%s %s⚙%s Installing %s...%s\n", ColorDim, progress, ColorReset, ColorCyan, ColorReset, task.name, note)
+	case taskSucceeded:
+		detail := ""
+		if task.detail != "" {
+			detail = ", " + task.detail
+		}
+		printf("   %s├─ %s%s %s✓%s %s %s(%s%s)%s\n", ColorDim, progress, ColorReset, ColorGreen, ColorReset, task.name, ColorDim, formatElapsed(task.elapsed), detail, ColorReset)
+	case taskFailed:
+		printf("   %s├─ %s%s %s✗%s %s (failed, %s)%s\n", ColorDim, progress, ColorReset, ColorRed, ColorReset, task.name, formatElapsed(task.elapsed), ColorReset)
+		for _, errLine := range task.errors {
+			printf("   %s│  %s→ %s%s\n", ColorDim, ColorYellow, errLine, ColorReset)
+		}
+	case taskSkipped:
+		printf("   %s├─ %s - %s (skipped)%s\n", ColorDim, progress, task.name, ColorReset)
+	}
+}
+
+// printFooter prints the overall result line in append-only mode
+func (p *ProgressRenderer) printFooter() {
+	printf("   %s└─ %d/%d done in %s%s\n", ColorDim, p.finished, len(p.tasks), formatElapsed(time.Since(p.started)), ColorReset)
+}
+
+// formatElapsed formats a duration for progress output (e.g. "850ms", "2.4s", "1m05s")
+func formatElapsed(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	default:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+}
+
+// inlineSpinner animates a single status line until stopped
+type inlineSpinner struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// showInlineSpinner shows a loading spinner on the current line
+// On non-interactive output nothing is animated
+func (c *CLITool) showInlineSpinner(message string) *inlineSpinner {
+	s := &inlineSpinner{stop: make(chan struct{}), done: make(chan struct{})}
+	if !console.Interactive {
+		close(s.done)
+		return s
+	}
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(80 * time.Millisecond)
+		defer ticker.Stop()
+		frames := spinnerFrameSet()
+		i := 0
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				printf("\r  %s%s%s %s", ColorCyan, frames[i%len(frames)], ColorReset, message)
+				i++
+			}
+		}
+	}()
+	return s
+}
+
+// clearInlineSpinner stops the spinner, waits for its last frame and clears the line
+func (c *CLITool) clearInlineSpinner(s *inlineSpinner) {
+	close(s.stop)
+	<-s.done
+	if console.Interactive {
+		printf("\r\033[K")
+	}
+}
@@ -3,7 +3,6 @@ package modules
 import (
 	"archive/zip"
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -28,346 +27,466 @@ const (
 )
 
 // InitProject initializes a new XyPriss project with all necessary configuration
-func (c *CLITool) InitProject(flags InitFlags) {
-	fmt.Println(XyPrissLogo)
+func (c *CLITool) InitProject(flags InitFlags) error {
+	started := time.Now()
+	printLogo()
 	
 	// Show loading animation
 	stop := c.showInlineSpinner("🚀 Initializing new XyPriss project...")
 	time.Sleep(500 * time.Millisecond)
 	c.clearInlineSpinner(stop)
-	fmt.Printf("🚀 %sInitializing new XyPriss project...%s\n\n", ColorGreen, ColorReset)
+	printf("🚀 %sInitializing new XyPriss project...%s\n\n", ColorGreen, ColorReset)
 
-	// Get project configuration interactively or from flags
-	config := GetProjectConfig(flags)
-	
-	// Display configuration in tree format
-	c.displayProjectConfig(config)
-
-	// Display configuration in tree format
-	c.displayProjectConfig(config)
+	// Get project configuration interactively or from flags (shown in tree format)
+	config, err := c.GetProjectConfig(flags)
+	if err != nil {
+		return err
+	}
 
 	// Download template with animation
-	fmt.Printf("\n%s┌─────────────────────────────────────────┐%s\n", ColorBlue, ColorReset)
-	fmt.Printf("%s│  📥 Downloading project template...    │%s\n", ColorBlue, ColorReset)
-	fmt.Printf("%s└─────────────────────────────────────────┘%s\n", ColorBlue, ColorReset)
+	printf("\n%s┌─────────────────────────────────────────┐%s\n", ColorBlue, ColorReset)
+	printf("%s│  📥 Downloading project template...    │%s\n", ColorBlue, ColorReset)
+	printf("%s└─────────────────────────────────────────┘%s\n", ColorBlue, ColorReset)
 	
-	templatePath, err := c.downloadTemplate()
+	templatePath, err := c.downloadTemplate(flags.Template)
 	if err != nil {
-		fmt.Printf("\n%s✗ Failed to download template:%s %v\n", ColorRed, ColorReset, err)
-		os.Exit(1)
+		return err
 	}
 	defer os.Remove(templatePath)
 
 	// Extract template with animation
-	fmt.Printf("\n%s┌─────────────────────────────────────────┐%s\n", ColorBlue, ColorReset)
-	fmt.Printf("%s│  📦 Extracting template...             │%s\n", ColorBlue, ColorReset)
-	fmt.Printf("%s└─────────────────────────────────────────┘%s\n", ColorBlue, ColorReset)
+	printf("\n%s┌─────────────────────────────────────────┐%s\n", ColorBlue, ColorReset)
+	printf("%s│  📦 Extracting template...             │%s\n", ColorBlue, ColorReset)
+	printf("%s└─────────────────────────────────────────┘%s\n", ColorBlue, ColorReset)
 	
 	err = c.extractTemplate(templatePath, config.Name, config.Language)
 	if err != nil {
-		fmt.Printf("\n%s✗ Failed to extract template:%s %v\n", ColorRed, ColorReset, err)
-		os.Exit(1)
+		return templateError(err, "failed to extract template")
 	}
-	fmt.Printf("  %s✓ Template extracted successfully%s\n", ColorGreen, ColorReset)
+	printf("  %s✓ Template extracted successfully%s\n", ColorGreen, ColorReset)
+	c.events.Emit(EventTemplateExtracted, map[string]interface{}{
+		"directory": config.Name,
+		"language":  config.Language,
+	})
 
 	// Customize configuration
-	fmt.Printf("\n%s┌─────────────────────────────────────────┐%s\n", ColorYellow, ColorReset)
-	fmt.Printf("%s│  🔧 Customizing configuration...       │%s\n", ColorYellow, ColorReset)
-	fmt.Printf("%s└─────────────────────────────────────────┘%s\n", ColorYellow, ColorReset)
+	printf("\n%s┌─────────────────────────────────────────┐%s\n", ColorYellow, ColorReset)
+	printf("%s│  🔧 Customizing configuration...       │%s\n", ColorYellow, ColorReset)
+	printf("%s└─────────────────────────────────────────┘%s\n", ColorYellow, ColorReset)
 	
 	c.customizePackageJson(config)
-	fmt.Printf("  %s✓ package.json configured%s\n", ColorGreen, ColorReset)
+	printf("  %s✓ package.json configured%s\n", ColorGreen, ColorReset)
+	c.emitConfigWritten(config.Name, "package.json")
 	
-	c.customizeEnvFile(config)
-	fmt.Printf("  %s✓ .env file configured%s\n", ColorGreen, ColorReset)
+	if err := c.customizeEnvFile(config); err != nil {
+		printf("  %s⚠ .env file not configured: %v%s\n", ColorYellow, err, ColorReset)
+	} else {
+		printf("  %s✓ .env file configured%s\n", ColorGreen, ColorReset)
+		c.emitConfigWritten(config.Name, ".env")
+	}
 	
-	c.createConfigFile(config)
-	fmt.Printf("  %s✓ xypriss.config.json created%s\n", ColorGreen, ColorReset)
+	if err := c.createConfigFile(config); err != nil {
+		printf("  %s⚠ xypriss.config.json not updated: %v%s\n", ColorYellow, err, ColorReset)
+	} else {
+		printf("  %s✓ xypriss.config.json created%s\n", ColorGreen, ColorReset)
+		c.emitConfigWritten(config.Name, "xypriss.config.json")
+	}
 	
 	c.customizeREADME(config)
-	fmt.Printf("  %s✓ README.md configured%s\n", ColorGreen, ColorReset)
+	printf("  %s✓ README.md configured%s\n", ColorGreen, ColorReset)
+	c.emitConfigWritten(config.Name, "README.md")
 
 	// Install dependencies with tree format
-	fmt.Printf("\n%s📦 Installing dependencies...%s\n", ColorMagenta, ColorReset)
-	c.installDependencies(config.Name, config.Language, flags.Mode, flags.Strict)
+	printf("\n%s📦 Installing dependencies...%s\n", ColorMagenta, ColorReset)
+	failedDeps, err := c.installDependencies(config.Name, config.Language, config.Mode, flags.Strict)
+	if err != nil {
+		return err
+	}
+
+	projectPath, _ := filepath.Abs(config.Name)
+	c.events.Emit(EventInitCompleted, map[string]interface{}{
+		"project":        config.Name,
+		"path":           projectPath,
+		"language":       config.Language,
+		"port":           config.Port,
+		"failedPackages": failedDeps,
+		"durationMs":     time.Since(started).Milliseconds(),
+	})
 
 	// Success message with beautiful formatting
-	fmt.Printf("\n%s╔═════════════════════════════════════════╗%s\n", ColorGreen, ColorReset)
-	fmt.Printf("%s║  ✨ Project '%s' initialized!          ║%s\n", ColorGreen, config.Name, ColorReset)
-	fmt.Printf("%s╚═════════════════════════════════════════╝%s\n", ColorGreen, ColorReset)
-	
-	fmt.Printf("\n%s📋 Next steps:%s\n", ColorBold, ColorReset)
-	fmt.Printf("  %s1.%s %scd %s%s\n", ColorCyan, ColorReset, ColorDim, config.Name, ColorReset)
-	fmt.Printf("  %s2.%s %snpm run dev%s\n", ColorCyan, ColorReset, ColorDim, ColorReset)
-	fmt.Printf("\n%s🎉 Happy coding with XyPriss!%s\n\n", ColorMagenta, ColorReset)
+	printf("\n%s╔═════════════════════════════════════════╗%s\n", ColorGreen, ColorReset)
+	printf("%s║  ✨ Project '%s' initialized!          ║%s\n", ColorGreen, config.Name, ColorReset)
+	printf("%s╚═════════════════════════════════════════╝%s\n", ColorGreen, ColorReset)
+	
+	printf("\n%s📋 Next steps:%s\n", ColorBold, ColorReset)
+	printf("  %s1.%s %scd %s%s\n", ColorCyan, ColorReset, ColorDim, config.Name, ColorReset)
+	printf("  %s2.%s %snpm run dev%s\n", ColorCyan, ColorReset, ColorDim, ColorReset)
+	printf("\n%s🎉 Happy coding with XyPriss!%s\n\n", ColorMagenta, ColorReset)
+	return nil
+}
+
+// emitConfigWritten emits a config.written event for a project file
+func (c *CLITool) emitConfigWritten(projectName, file string) {
+	c.events.Emit(EventConfigWritten, map[string]interface{}{
+		"file": filepath.Join(projectName, file),
+	})
+}
+
+// requireProject returns an environment error when the current directory has no package.json
+func requireProject() error {
+	if _, err := os.Stat("package.json"); os.IsNotExist(err) {
+		return environmentError("no package.json found in current directory").
+			WithHint("Make sure you're in a XyPriss project directory, or run 'xypcli init' to create one")
+	}
+	return nil
 }
 
 // InstallPackage installs a single package using the XyPriss installation system
-func (c *CLITool) InstallPackage(packageName string) {
-	fmt.Printf("%s📦 Installing dependencies...%s\n", ColorMagenta, ColorReset)
+func (c *CLITool) InstallPackage(packageName string) error {
+	return c.InstallPackages([]string{packageName}, "")
+}
+
+// InstallPackages installs multiple packages using the XyPriss installation system with intelligent parallelization
+func (c *CLITool) InstallPackages(packages []string, mode string) error {
+	printf("%s📦 Installing %d package(s)...%s\n", ColorMagenta, len(packages), ColorReset)
 
 	// Check if we're in a XyPriss project directory
-	if _, err := os.Stat("package.json"); os.IsNotExist(err) {
-		fmt.Printf("  %s✗ No package.json found in current directory%s\n", ColorRed, ColorReset)
-		fmt.Printf("%sMake sure you're in a XyPriss project directory%s\n", ColorYellow, ColorReset)
-		return
+	if err := requireProject(); err != nil {
+		return err
 	}
 
-	// Check if Bun is available for faster installation
-	useBun := false
-	if _, err := exec.LookPath("bun"); err == nil {
-		useBun = true
-		fmt.Printf("\n  %s⚡ Using 'BMode' for faster installation%s\n", ColorCyan, ColorReset)
-	} else {
-		fmt.Printf("\n  %s→ Bun not found, using npm%s\n", ColorYellow, ColorReset)
-		// Check npm availability
-		if _, err := exec.LookPath("npm"); err != nil {
-			fmt.Printf("  %s✗ npm is not installed%s\n", ColorRed, ColorReset)
-			return
-		}
+	// Determine installation mode
+	useBun, err := c.selectPackageManager(mode, false)
+	if err != nil {
+		return err
 	}
 
-	// Use the same style as installDependencies
-	fmt.Printf("%s│%s\n", ColorDim, ColorReset)
-	fmt.Printf("%s└─ Package (1)%s\n", ColorDim, ColorReset)
-	
-	// Install the single package using the existing system
-	var failedDeps []string
-	c.installSingleDependency(".", packageName, false, useBun, 1, 1, &failedDeps, true, false)
+	// Resolve per-package install policies
+	policies := LoadInstallPolicies(".")
+	if useBun {
+		c.applyTrustedDependencies(".", policies, packages)
+	}
+
+	// Use parallelization for faster installation
+	printf("  %s⚡ Parallel installation enabled%s\n", ColorCyan, ColorReset)
+	printf("%s│%s\n", ColorDim, ColorReset)
+
+	// Install packages in parallel with intelligent batching
+	totalPackages := len(packages)
+	failedDeps := c.runParallelInstall(".", []installGroup{{title: "Packages", packages: packages}}, useBun, policies, false)
 
 	// Final summary
-	fmt.Printf("\n")
+	printf("\n")
 	if len(failedDeps) > 0 {
-		fmt.Printf("%s⚠ Installation completed with warnings%s\n", ColorYellow, ColorReset)
-		fmt.Printf("%s├─ Failed: %d/%d packages%s\n", ColorDim, len(failedDeps), 1, ColorReset)
-		for _, dep := range failedDeps {
-			prefix := "└─"
-			fmt.Printf("%s%s ✗ %s%s\n", ColorDim, prefix, dep, ColorReset)
+		printf("%s⚠ Installation completed with warnings%s\n", ColorYellow, ColorReset)
+		printf("%s├─ Failed: %d/%d packages%s\n", ColorDim, len(failedDeps), totalPackages, ColorReset)
+		for i, dep := range failedDeps {
+			prefix := "├─"
+			if i == len(failedDeps)-1 {
+				prefix = "└─"
+			}
+			printf("%s%s ✗ %s%s\n", ColorDim, prefix, dep, ColorReset)
 		}
-	} else {
-		fmt.Printf("%s✨ Package installed successfully!%s\n", ColorGreen, ColorReset)
-		fmt.Printf("%s└─ 1/1 packages%s\n", ColorDim, 1, ColorReset)
+		return installError("%d/%d package(s) failed to install: %s", len(failedDeps), totalPackages, strings.Join(failedDeps, ", "))
 	}
-}
 
-// InstallPackages installs multiple packages using the XyPriss installation system with intelligent parallelization
-func (c *CLITool) InstallPackages(packages []string, mode string) {
-	fmt.Printf("%s📦 Installing %d package(s)...%s\n", ColorMagenta, len(packages), ColorReset)
+	writeInstallStamp(".")
+	printf("%s✨ All packages installed successfully!%s\n", ColorGreen, ColorReset)
+	printf("%s└─ %d/%d packages%s\n", ColorDim, totalPackages, totalPackages, ColorReset)
+	return nil
+}
 
-	// Check if we're in a XyPriss project directory
-	if _, err := os.Stat("package.json"); os.IsNotExist(err) {
-		fmt.Printf("  %s✗ No package.json found in current directory%s\n", ColorRed, ColorReset)
-		fmt.Printf("%sMake sure you're in a XyPriss project directory%s\n", ColorYellow, ColorReset)
-		return
+// selectPackageManager resolves the installation mode ('b', 'n' or auto)
+// Returns whether Bun is used. In auto mode, Bun is installed first when
+// tryInstallBun is set and Bun is missing
+func (c *CLITool) selectPackageManager(mode string, tryInstallBun bool) (bool, error) {
+	npmMissing := func() error {
+		return environmentError("npm is not installed").WithHint("Install Node.js from https://nodejs.org or Bun from https://bun.sh")
 	}
 
-	// Determine installation mode
-	useBun := false
 	if mode == "b" {
 		// Force bun mode
 		if _, err := exec.LookPath("bun"); err == nil {
-			useBun = true
-			fmt.Printf("\n  %s⚡ Using 'BMode' (forced)%s\n", ColorCyan, ColorReset)
-		} else {
-			fmt.Printf("\n  %s✗ Bun not found, falling back to npm%s\n", ColorRed, ColorReset)
-			if _, err := exec.LookPath("npm"); err != nil {
-				fmt.Printf("  %s✗ npm is not installed%s\n", ColorRed, ColorReset)
-				return
-			}
+			printf("\n  %s⚡ Using 'BMode' (forced)%s\n", ColorCyan, ColorReset)
+			return true, nil
+		}
+		printf("\n  %s✗ Bun not found, falling back to npm%s\n", ColorRed, ColorReset)
+		if _, err := exec.LookPath("npm"); err != nil {
+			return false, npmMissing()
 		}
-	} else if mode == "n" {
+		return false, nil
+	}
+
+	if mode == "n" {
 		// Force npm mode
-		fmt.Printf("\n  %s→ Using npm (forced)%s\n", ColorCyan, ColorReset)
+		printf("\n  %s→ Using npm (forced)%s\n", ColorCyan, ColorReset)
 		if _, err := exec.LookPath("npm"); err != nil {
-			fmt.Printf("  %s✗ npm is not installed%s\n", ColorRed, ColorReset)
-			return
+			return false, npmMissing()
 		}
-	} else {
-		// Auto-detect mode
-		if _, err := exec.LookPath("bun"); err == nil {
-			useBun = true
-			fmt.Printf("\n  %s⚡ Using 'BMode' for faster installation%s\n", ColorCyan, ColorReset)
-		} else {
-			fmt.Printf("\n  %s→ Bun not found, using npm%s\n", ColorYellow, ColorReset)
-			// Check npm availability
-			if _, err := exec.LookPath("npm"); err != nil {
-				fmt.Printf("  %s✗ npm is not installed%s\n", ColorRed, ColorReset)
-				return
-			}
+		return false, nil
+	}
+
+	// Auto-detect mode
+	if _, err := exec.LookPath("bun"); err == nil {
+		printf("\n  %s⚡ Using 'BMode' for faster installation%s\n", ColorCyan, ColorReset)
+		return true, nil
+	}
+
+	if tryInstallBun {
+		// Try to install Bun first
+		printf("\n  %s→ Bun not found, attempting to install...%s\n", ColorYellow, ColorReset)
+		if c.installBun() {
+			printf("  %s✓ Bun installed successfully%s\n", ColorGreen, ColorReset)
+			return true, nil
 		}
+		printf("  %s→ Falling back to npm%s\n", ColorYellow, ColorReset)
+	} else {
+		printf("\n  %s→ Bun not found, using npm%s\n", ColorYellow, ColorReset)
 	}
 
-	// Use parallelization for faster installation
-	fmt.Printf("  %s⚡ Parallel installation enabled%s\n", ColorCyan, ColorReset)
-	fmt.Printf("%s│%s\n", ColorDim, ColorReset)
-	fmt.Printf("%s└─ Packages (%d)%s\n", ColorDim, len(packages), ColorReset)
+	// Check npm availability
+	if _, err := exec.LookPath("npm"); err != nil {
+		return false, npmMissing()
+	}
+	return false, nil
+}
 
-	// Install packages in parallel with intelligent batching
-	totalPackages := len(packages)
-	failedDeps := make([]string, 0)
-	
-	// Use channels for parallel installation
-	type installResult struct {
+// installGroup is a titled set of packages installed by runParallelInstall
+type installGroup struct {
+	title    string
+	packages []string
+	isDev    bool
+}
+
+// runParallelInstall installs groups of packages concurrently while a single
+// ProgressRenderer owns the terminal. Returns the packages that failed
+// (dev packages are suffixed with " (dev)"). In strict mode, packages that have
+// not started yet are skipped after the first failure
+func (c *CLITool) runParallelInstall(projectDir string, groups []installGroup, useBun bool, policies InstallPolicies, strict bool) []string {
+	type installJob struct {
+		id          int
 		packageName string
-		success     bool
-		index       int
+		isDev       bool
 	}
-	
+
+	renderer := NewProgressRenderer()
+	jobs := []installJob{}
+	for _, group := range groups {
+		if len(group.packages) == 0 {
+			continue
+		}
+		gi := renderer.AddGroup(group.title)
+		for _, pkg := range group.packages {
+			id := renderer.AddTask(gi, pkg, policyNote(policies, pkg, useBun))
+			jobs = append(jobs, installJob{id: id, packageName: pkg, isDev: group.isDev})
+		}
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
 	// Limit concurrent installations to avoid overwhelming the system
-	maxConcurrent := 4
-	if totalPackages < maxConcurrent {
-		maxConcurrent = totalPackages
+	maxConcurrent := c.settings.Int("concurrency")
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
 	}
-	
-	results := make(chan installResult, totalPackages)
+	if len(jobs) < maxConcurrent {
+		maxConcurrent = len(jobs)
+	}
+
+	var (
+		mu      sync.Mutex
+		failed  []string
+		aborted bool
+		wg      sync.WaitGroup
+	)
 	semaphore := make(chan struct{}, maxConcurrent)
-	
-	// Start all installations
-	for i, pkg := range packages {
-		go func(index int, packageName string) {
-			semaphore <- struct{}{} // Acquire semaphore
+
+	renderer.Run()
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job installJob) {
+			defer wg.Done()
+			semaphore <- struct{}{}        // Acquire semaphore
 			defer func() { <-semaphore }() // Release semaphore
-			
-			success := c.installPackageParallel(".", packageName, useBun, index+1, totalPackages)
-			results <- installResult{packageName: packageName, success: success, index: index}
-		}(i, pkg)
-	}
-	
-	// Collect results
-	completed := 0
-	for completed < totalPackages {
-		result := <-results
-		if !result.success {
-			failedDeps = append(failedDeps, result.packageName)
-		}
-		completed++
-	}
 
-	// Final summary
-	fmt.Printf("\n")
-	if len(failedDeps) > 0 {
-		fmt.Printf("%s⚠ Installation completed with warnings%s\n", ColorYellow, ColorReset)
-		fmt.Printf("%s├─ Failed: %d/%d packages%s\n", ColorDim, len(failedDeps), totalPackages, ColorReset)
-		for i, dep := range failedDeps {
-			prefix := "├─"
-			if i == len(failedDeps)-1 {
-				prefix = "└─"
+			mu.Lock()
+			skip := aborted
+			mu.Unlock()
+			if skip {
+				renderer.Skip(job.id)
+				return
 			}
-			fmt.Printf("%s%s ✗ %s%s\n", ColorDim, prefix, dep, ColorReset)
-		}
-	} else {
-		fmt.Printf("%s✨ All packages installed successfully!%s\n", ColorGreen, ColorReset)
-		fmt.Printf("%s└─ %d/%d packages%s\n", ColorDim, totalPackages, totalPackages, ColorReset)
-	}
-}
 
-// installPackageParallel installs a single package in parallel mode
-func (c *CLITool) installPackageParallel(projectDir, packageName string, useBun bool, current, total int) bool {
-	var cmd *exec.Cmd
-	
-	// Special case: nquickdev needs npm for postinstall scripts (Bun ignores them)
-	if useBun && packageName == "nquickdev" {
-		cmd = exec.Command("npm", "install", packageName)
-	} else if useBun {
-		cmd = exec.Command("bun", "add", packageName)
-	} else {
-		cmd = exec.Command("npm", "install", packageName)
-	}
-	cmd.Dir = projectDir
+			started := time.Now()
+			outcome := c.runPackageInstall(projectDir, job.packageName, job.isDev, useBun, policies, func() {
+				started = time.Now()
+				renderer.Start(job.id)
+				c.events.Emit(EventPackageInstallStarted, map[string]interface{}{
+					"package": job.packageName,
+					"dev":     job.isDev,
+					"manager": resolveInstallManager(policyFor(policies, job.packageName), useBun),
+				})
+			})
+			eventData := map[string]interface{}{
+				"package":    job.packageName,
+				"dev":        job.isDev,
+				"manager":    outcome.manager,
+				"durationMs": time.Since(started).Milliseconds(),
+			}
+			if outcome.err != nil {
+				renderer.Fail(job.id, outcome.errors)
+				eventData["errorClass"] = outcome.errorClass
+				eventData["error"] = strings.Join(outcome.errors, "\n")
+				c.events.Emit(EventPackageInstallFailed, eventData)
+				label := job.packageName
+				if job.isDev {
+					label += " (dev)"
+				}
+				mu.Lock()
+				failed = append(failed, label)
+				if strict {
+					aborted = true
+				}
+				mu.Unlock()
+				return
+			}
+			renderer.Succeed(job.id, outcome.detail)
+			c.events.Emit(EventPackageInstallSucceeded, eventData)
+		}(job)
+	}
+	wg.Wait()
+	renderer.Stop()
+
+	c.events.Emit(EventInstallCompleted, map[string]interface{}{
+		"total":   len(jobs),
+		"failed":  failed,
+		"aborted": aborted,
+	})
+	return failed
+}
 
-	// Progress indicator
-	progress := fmt.Sprintf("[%d/%d]", current, total)
+// installOutcome is the result of a single package installation
+type installOutcome struct {
+	manager    string   // Package manager that ran the install
+	detail     string   // Short result detail (e.g. Bun's package count)
+	errors     []string // Relevant error lines on failure
+	errorClass string   // Stable error class for machine-readable output
+	err        error
+}
 
-	// Show inline progress
-	fmt.Printf("   %s├─ %s%s %s⚙%s Installing %s...%s\n", 
-		ColorDim, progress, ColorReset, ColorCyan, ColorReset, packageName, ColorReset)
+// runPackageInstall installs a single package and returns its outcome
+// onStart is called once the install actually begins (after waiting for the npm lock)
+func (c *CLITool) runPackageInstall(projectDir, packageName string, isDev, useBun bool, policies InstallPolicies, onStart func()) installOutcome {
+	cmd, manager := buildInstallCommand(policies, projectDir, packageName, isDev, useBun, c.settings.String("registry"))
 
 	// Concurrent npm installs in the same directory cause race conditions (e.g., ENOTEMPTY, ENOENT)
 	// We use a mutex for npm to ensure stability while maintaining the goroutine/channel architecture
-	if !useBun {
+	if manager == "npm" {
 		npmMutex.Lock()
 		defer npmMutex.Unlock()
 	}
+	onStart()
 
 	var stderr bytes.Buffer
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	if err := cmd.Run(); err != nil {
+		return installOutcome{
+			manager:    manager,
+			errors:     extractInstallErrors(stderr.String()),
+			errorClass: classifyInstallError(stdout.String() + stderr.String()),
+			err:        err,
+		}
+	}
 
-	if err != nil {
-		fmt.Printf("   %s├─ %s%s %s✗%s %s (failed)%s\n", 
-			ColorDim, progress, ColorReset, ColorRed, ColorReset, packageName, ColorReset)
-		
-		// Show detailed error messages
-		errOutput := stderr.String()
-		if errOutput != "" {
-			// Extract and display all relevant error lines (prioritize actual errors)
-			lines := strings.Split(errOutput, "\n")
-			errorLines := []string{}
-			
-			for _, line := range lines {
-				line = strings.TrimSpace(line)
-				if line != "" {
-					// Collect all error-related lines, but EXCLUDE warnings
-					// npm warnings are not fatal errors and shouldn't be highlighted as reasons for failure
-					if (strings.Contains(line, "ERR!") || 
-					   strings.Contains(line, "error") || 
-					   strings.Contains(line, "404") ||
-					   strings.Contains(line, "ENOENT") ||
-					   strings.Contains(line, "ENOTEMPTY") ||
-					   strings.Contains(line, "code")) &&
-					   !strings.Contains(strings.ToLower(line), "warn") {
-						errorLines = append(errorLines, line)
-					}
-				}
-			}
-			
-			// If we didn't find specific error lines but the command still failed,
-			// fallback to showing the first few lines of stderr
-			if len(errorLines) == 0 && errOutput != "" {
-				for _, line := range strings.Split(errOutput, "\n") {
-					line = strings.TrimSpace(line)
-					if line != "" && len(errorLines) < 3 {
-						errorLines = append(errorLines, line)
-					}
-				}
-			}
-			
-			// Display relevant error lines
-			maxLines := 5
-			if len(errorLines) > maxLines {
-				errorLines = errorLines[:maxLines]
+	outcome := installOutcome{manager: manager}
+	if manager == "bun" {
+		outcome.detail = bunInstallSummary(stdout.String() + stderr.String())
+	}
+	return outcome
+}
+
+// extractInstallErrors picks the relevant error lines from package manager output
+func extractInstallErrors(errOutput string) []string {
+	if errOutput == "" {
+		return nil
+	}
+
+	// Extract and display all relevant error lines (prioritize actual errors)
+	lines := strings.Split(errOutput, "\n")
+	errorLines := []string{}
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			// Collect all error-related lines, but EXCLUDE warnings
+			// npm warnings are not fatal errors and shouldn't be highlighted as reasons for failure
+			if (strings.Contains(line, "ERR!") ||
+				strings.Contains(line, "error") ||
+				strings.Contains(line, "404") ||
+				strings.Contains(line, "ENOENT") ||
+				strings.Contains(line, "ENOTEMPTY") ||
+				strings.Contains(line, "code")) &&
+				!strings.Contains(strings.ToLower(line), "warn") {
+				errorLines = append(errorLines, line)
 			}
-			
-			for _, errLine := range errorLines {
-				fmt.Printf("   %s│  %s→ %s%s\n", ColorDim, ColorYellow, errLine, ColorReset)
+		}
+	}
+
+	// If we didn't find specific error lines but the command still failed,
+	// fallback to showing the first few lines of stderr
+	if len(errorLines) == 0 {
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+			if line != "" && len(errorLines) < 3 {
+				errorLines = append(errorLines, line)
 			}
 		}
-		return false
 	}
 
-	fmt.Printf("   %s├─ %s%s %s✓%s %s%s\n", 
-		ColorDim, progress, ColorReset, ColorGreen, ColorReset, packageName, ColorReset)
-	return true
+	maxLines := 5
+	if len(errorLines) > maxLines {
+		errorLines = errorLines[:maxLines]
+	}
+	return errorLines
 }
 
-
+// bunInstallSummary extracts the "N packages installed" line from Bun output
+func bunInstallSummary(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.Contains(line, "packages installed") || strings.Contains(line, "package installed") {
+			return line
+		}
+	}
+	return ""
+}
 
 // downloadTemplate downloads the project template
-func (c *CLITool) downloadTemplate() (string, error) {
+// source is a URL or a local zip ("" is the Nehonix SDK template)
+func (c *CLITool) downloadTemplate(source string) (string, error) {
 	tempFile, err := ioutil.TempFile("", "xypriss-template-*.zip")
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %v", err)
+		return "", failureError(err, "failed to create temp file")
 	}
 	defer tempFile.Close()
 
 	platformOS, arch, _ := GetPlatformInfo()
-	fmt.Printf("  %s→ Platform: %s/%s%s\n", ColorDim, platformOS, arch, ColorReset)
+	printf("  %s→ Platform: %s/%s%s\n", ColorDim, platformOS, arch, ColorReset)
 
 	templateURL := NehonixSDKURL + "initdr.zip"
-	fmt.Printf("  %s→ Source: dll.nehonix.com%s\n", ColorDim, ColorReset)
+	switch {
+	case source == "":
+		printf("  %s→ Source: dll.nehonix.com%s\n", ColorDim, ColorReset)
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		templateURL = source
+		printf("  %s→ Source: %s%s\n", ColorDim, source, ColorReset)
+	default:
+		return c.copyLocalTemplate(tempFile, source)
+	}
 
 	// Show spinner during download
 	stop := c.showInlineSpinner("Downloading...")
@@ -375,31 +494,51 @@ func (c *CLITool) downloadTemplate() (string, error) {
 	c.clearInlineSpinner(stop)
 
 	if err != nil {
-		fmt.Printf("  %s⚠ Nehonix SDK unavailable, using local template%s\n", ColorYellow, ColorReset)
-		localTemplate, err := os.Open(LocalTemplatePath)
-		if err != nil {
-			return "", fmt.Errorf("failed to open local template: %v", err)
+		printf("  %s⚠ Nehonix SDK unavailable, using local template%s\n", ColorYellow, ColorReset)
+		if _, statErr := os.Stat(LocalTemplatePath); statErr != nil {
+			return "", networkError(err, "template server unreachable and no local template found").
+				WithHint("Check your network connection, or place %s in the current directory", LocalTemplatePath)
 		}
-		defer localTemplate.Close()
+		return c.copyLocalTemplate(tempFile, LocalTemplatePath)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", templateError(nil, "failed to download template: HTTP %d", resp.StatusCode)
+	}
 
-		_, err = io.Copy(tempFile, localTemplate)
-		if err != nil {
-			return "", fmt.Errorf("failed to copy local template: %v", err)
-		}
-		fmt.Printf("  %s✓ Local template loaded%s\n", ColorGreen, ColorReset)
-	} else {
-		defer resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			return "", fmt.Errorf("failed to download template: HTTP %d", resp.StatusCode)
-		}
+	size, err := io.Copy(tempFile, resp.Body)
+	if err != nil {
+		return "", networkError(err, "failed to download template")
+	}
+	printf("  %s✓ Template downloaded%s\n", ColorGreen, ColorReset)
+	c.events.Emit(EventTemplateDownloaded, map[string]interface{}{
+		"source": "remote",
+		"url":    templateURL,
+		"bytes":  size,
+	})
 
-		_, err = io.Copy(tempFile, resp.Body)
-		if err != nil {
-			return "", fmt.Errorf("failed to save template: %v", err)
-		}
-		fmt.Printf("  %s✓ Template downloaded%s\n", ColorGreen, ColorReset)
+	return tempFile.Name(), nil
+}
+
+// copyLocalTemplate copies a local template zip into the temporary template file
+func (c *CLITool) copyLocalTemplate(tempFile *os.File, path string) (string, error) {
+	localTemplate, err := os.Open(path)
+	if err != nil {
+		return "", templateError(err, "failed to open local template %s", path).
+			WithHint("Check the 'template' setting with 'xypcli config get template'")
 	}
+	defer localTemplate.Close()
 
+	size, err := io.Copy(tempFile, localTemplate)
+	if err != nil {
+		return "", templateError(err, "failed to copy local template")
+	}
+	printf("  %s✓ Local template loaded%s\n", ColorGreen, ColorReset)
+	c.events.Emit(EventTemplateDownloaded, map[string]interface{}{
+		"source": "local",
+		"path":   path,
+		"bytes":  size,
+	})
 	return tempFile.Name(), nil
 }
 
@@ -468,77 +607,129 @@ func (c *CLITool) customizePackageJson(config ProjectConfig) {
 		return
 	}
 
-	var packageJson map[string]interface{}
-	if err := json.Unmarshal(data, &packageJson); err != nil {
+	// Only the changed values are rewritten: the template's layout and dependencies are kept
+	doc, err := parseJSONDocument(packagePath, data)
+	if err != nil {
 		log.Printf("Warning: Could not parse package.json: %v", err)
 		return
 	}
 
-	packageJson["name"] = strings.ToLower(strings.ReplaceAll(config.Name, " ", "-"))
-	packageJson["description"] = config.Description
-	packageJson["dependencies"] = make(map[string]interface{})
-	packageJson["devDependencies"] = make(map[string]interface{})
+	doc.set(packageName(config.Name, ""), "name")
+	doc.set(config.Description, "description")
+	if config.License != "" {
+		doc.set(config.License, "license")
+	}
 
-	updatedData, _ := json.MarshalIndent(packageJson, "", "  ")
-	ioutil.WriteFile(packagePath, updatedData, 0644)
+	if err := doc.write(); err != nil {
+		log.Printf("Warning: Could not write package.json: %v", err)
+	}
 }
 
-// customizeEnvFile modifies the .env file
-func (c *CLITool) customizeEnvFile(config ProjectConfig) {
+// customizeEnvFile sets PORT in the .env file (keeping its comments) and lists its keys in .env.example
+// A template without .env gets one, copied from its .env.example when there is one
+func (c *CLITool) customizeEnvFile(config ProjectConfig) error {
 	envPath := filepath.Join(config.Name, ".env")
 
-	data, err := ioutil.ReadFile(envPath)
+	dotenv, err := readDotenvFile(envPath)
 	if err != nil {
-		log.Printf("Warning: Could not read .env file: %v", err)
-		return
+		return err
+	}
+	if !dotenv.exists {
+		example, err := readDotenvFile(filepath.Join(config.Name, EnvExampleFile))
+		if err != nil {
+			return err
+		}
+		dotenv.entries = example.entries
+		source := "empty"
+		if example.exists {
+			source = "from " + EnvExampleFile
+		}
+		printf("  %s→ The template has no .env, creating it (%s)%s\n", ColorDim, source, ColorReset)
 	}
 
-	envContent := string(data)
-	envContent = strings.ReplaceAll(envContent, "{{PORT}}", fmt.Sprintf("%d", config.Port))
-	envContent = strings.ReplaceAll(envContent, "PORT=8080", fmt.Sprintf("PORT=%d", config.Port))
-	ioutil.WriteFile(envPath, []byte(envContent), 0644)
+	port := fmt.Sprintf("%d", config.Port)
+	values := dotenv.values()
+	for _, key := range dotenv.keys() {
+		if strings.Contains(values[key], "{{PORT}}") {
+			dotenv.set(key, strings.ReplaceAll(values[key], "{{PORT}}", port))
+		}
+	}
+	dotenv.set("PORT", port)
+
+	// Replace the template's placeholder JWT secret with a random one
+	if config.WithAuth {
+		secret, err := randomSecret(48)
+		if err != nil {
+			return fmt.Errorf("cannot generate a JWT secret: %v", err)
+		}
+		jwtKeys := []string{}
+		for _, key := range dotenv.keys() {
+			if upper := strings.ToUpper(key); strings.Contains(upper, "JWT") && strings.Contains(upper, "SECRET") {
+				jwtKeys = append(jwtKeys, key)
+			}
+		}
+		if len(jwtKeys) == 0 {
+			jwtKeys = append(jwtKeys, "JWT_SECRET")
+		}
+		for _, key := range jwtKeys {
+			dotenv.set(key, secret)
+		}
+	}
+	if err := dotenv.write(); err != nil {
+		return err
+	}
+
+	for _, key := range dotenv.keys() {
+		if _, err := syncEnvExample(config.Name, key, true); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // createConfigFile creates or updates the xypriss.config.json file with system variables
 // If the file already exists, it merges the __sys__ section without touching other data
-func (c *CLITool) createConfigFile(config ProjectConfig) {
-	configPath := filepath.Join(config.Name, "xypriss.config.json")
-
-	// System configuration to add/update
-	sysConfig := map[string]interface{}{
-		"__version__":     config.Version,
-		"__author__":      config.Author,
-		"__name__":        config.Name,
-		"__description__": config.Description,
-		"__alias__":       config.AppAlias,
-		"__port__":        config.Port,
-		"__PORT__":        config.Port,
-	}
-
-	// Try to read existing config file
-	var existingConfig map[string]interface{}
-	if data, err := ioutil.ReadFile(configPath); err == nil {
-		// File exists, parse it
-		if err := json.Unmarshal(data, &existingConfig); err != nil {
-			log.Printf("Warning: Could not parse existing config, will create new: %v", err)
-			existingConfig = make(map[string]interface{})
+// A file that cannot be parsed is left untouched and reported
+func (c *CLITool) createConfigFile(config ProjectConfig) error {
+	configPath := filepath.Join(config.Name, XyPrissConfigFile)
+
+	// System configuration to add/update (see schema.go), in the order it is written;
+	// empty values are skipped
+	fields := []struct {
+		key   string
+		value interface{}
+	}{
+		{"__version__", config.Version},
+		{"__author__", config.Author},
+		{"__name__", config.Name},
+		{"__description__", config.Description},
+		{"__alias__", config.AppAlias},
+		{"__port__", config.Port},
+		{"__PORT__", config.Port},
+		{"__env__", config.Env},
+	}
+
+	// Update the existing config in place, keeping its layout and keys such as __app_urls__
+	doc, err := readJSONDocument(configPath)
+	if os.IsNotExist(err) {
+		doc = newJSONDocument(configPath)
+	} else if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		if field.value == "" || field.value == 0 {
+			continue
+		}
+		if err := doc.set(field.value, "__sys__", field.key); err != nil {
+			return fmt.Errorf("cannot set __sys__.%s: %v", field.key, err)
 		}
-	} else {
-		// File doesn't exist, create new config
-		existingConfig = make(map[string]interface{})
 	}
 
-	// Merge __sys__ section into existing config
-	existingConfig["__sys__"] = sysConfig
-
-	// Write merged config back to file
-	data, err := json.MarshalIndent(existingConfig, "", "  ")
-	if err != nil {
-		log.Printf("Warning: Could not marshal config: %v", err)
-		return
+	if err := doc.write(); err != nil {
+		return fmt.Errorf("cannot write %s: %v", configPath, err)
 	}
-
-	ioutil.WriteFile(configPath, data, 0644)
+	return nil
 }
 
 // customizeREADME modifies the README.md file
@@ -577,12 +768,12 @@ func (c *CLITool) customizeREADME(config ProjectConfig) {
 }
 
 // installDependencies installs project dependencies using Bun or npm
-func (c *CLITool) installDependencies(projectName string, language string, mode string, strict bool) {
+// Returns the packages that failed to install; in strict mode any failure is an error
+func (c *CLITool) installDependencies(projectName string, language string, mode string, strict bool) ([]string, error) {
 	configPath := filepath.Join(projectName, ".config")
 	data, err := ioutil.ReadFile(configPath)
 	if err != nil {
-		fmt.Printf("  %s✗ Failed to read .config file%s\n", ColorRed, ColorReset)
-		return
+		return nil, templateError(err, "template is missing its .config dependency list")
 	}
 
 	// Parse dependencies from .config
@@ -618,140 +809,53 @@ func (c *CLITool) installDependencies(projectName string, language string, mode
 	os.Remove(configPath)
 
 	// Determine installation mode
-	useBun := false
-	if mode == "b" {
-		// Force bun mode
-		if _, err := exec.LookPath("bun"); err == nil {
-			useBun = true
-			fmt.Printf("\n  %s⚡ Using 'BMode' (forced)%s\n", ColorCyan, ColorReset)
-		} else {
-			fmt.Printf("\n  %s✗ Bun not found, falling back to npm%s\n", ColorRed, ColorReset)
-			if _, err := exec.LookPath("npm"); err != nil {
-				fmt.Printf("  %s✗ npm is not installed%s\n", ColorRed, ColorReset)
-				return
-			}
-		}
-	} else if mode == "n" {
-		// Force npm mode
-		fmt.Printf("\n  %s→ Using npm (forced)%s\n", ColorCyan, ColorReset)
-		if _, err := exec.LookPath("npm"); err != nil {
-			fmt.Printf("  %s✗ npm is not installed%s\n", ColorRed, ColorReset)
-			return
-		}
-	} else {
-		// Auto-detect mode
-		if _, err := exec.LookPath("bun"); err == nil {
-			useBun = true
-			fmt.Printf("\n  %s⚡ Using 'BMode' for faster installation%s\n", ColorCyan, ColorReset)
-		} else {
-			// Try to install Bun first
-			fmt.Printf("\n  %s→ Bun not found, attempting to install...%s\n", ColorYellow, ColorReset)
-			if c.installBun() {
-				useBun = true
-				fmt.Printf("  %s✓ Bun installed successfully%s\n", ColorGreen, ColorReset)
-			} else {
-				fmt.Printf("  %s→ Falling back to npm%s\n", ColorYellow, ColorReset)
-				// Check npm availability
-				if _, err := exec.LookPath("npm"); err != nil {
-					fmt.Printf("  %s✗ npm is not installed%s\n", ColorRed, ColorReset)
-					return
-				}
-			}
-		}
+	useBun, err := c.selectPackageManager(mode, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve per-package install policies (the template may ship its own)
+	policies := LoadInstallPolicies(projectName)
+	if useBun {
+		c.applyTrustedDependencies(projectName, policies, append(append([]string{}, deps...), devDeps...))
 	}
 
 	totalDeps := len(deps) + len(devDeps)
-	failedDeps := make([]string, 0)
 
 	// Use parallelization for faster installation
-	fmt.Printf("  %s⚡ Parallel installation enabled%s\n", ColorCyan, ColorReset)
-	fmt.Printf("%s│%s\n", ColorDim, ColorReset)
+	printf("  %s⚡ Parallel installation enabled%s\n", ColorCyan, ColorReset)
+	printf("%s│%s\n", ColorDim, ColorReset)
 
 	// Install packages in parallel with intelligent batching
-	type installResult struct {
-		packageName string
-		success     bool
-		isDev       bool
-		index       int
-	}
-	
-	// Limit concurrent installations to avoid overwhelming the system
-	maxConcurrent := 4
-	if totalDeps < maxConcurrent {
-		maxConcurrent = totalDeps
-	}
-	
-	results := make(chan installResult, totalDeps)
-	semaphore := make(chan struct{}, maxConcurrent)
-	
-	// Start all installations (regular dependencies)
-	if len(deps) > 0 {
-		fmt.Printf("%s├─ Dependencies (%d)%s\n", ColorDim, len(deps), ColorReset)
-		for i, dep := range deps {
-			go func(index int, packageName string) {
-				semaphore <- struct{}{} // Acquire semaphore
-				defer func() { <-semaphore }() // Release semaphore
-				
-				success := c.installPackageParallel(projectName, packageName, useBun, index+1, totalDeps)
-				results <- installResult{packageName: packageName, success: success, isDev: false, index: index}
-			}(i, dep)
-		}
-	}
-	
-	// Start all installations (dev dependencies)
-	if len(devDeps) > 0 {
-		if len(deps) > 0 {
-			fmt.Printf("%s│%s\n", ColorDim, ColorReset)
-		}
-		fmt.Printf("%s└─ Dev Dependencies (%d)%s\n", ColorDim, len(devDeps), ColorReset)
-		for i, dep := range devDeps {
-			go func(index int, packageName string) {
-				semaphore <- struct{}{} // Acquire semaphore
-				defer func() { <-semaphore }() // Release semaphore
-				
-				success := c.installPackageParallel(projectName, packageName, useBun, len(deps)+index+1, totalDeps)
-				results <- installResult{packageName: packageName, success: success, isDev: true, index: index}
-			}(i, dep)
-		}
-	}
-	
-	// Collect results
-	completed := 0
-	for completed < totalDeps {
-		result := <-results
-		if !result.success {
-			devLabel := ""
-			if result.isDev {
-				devLabel = " (dev)"
-			}
-			failedDeps = append(failedDeps, result.packageName+devLabel)
-			
-			// In strict mode, exit immediately on first error
-			if strict {
-				fmt.Printf("\n%s✗ Installation failed in strict mode%s\n", ColorRed, ColorReset)
-				fmt.Printf("%s└─ Failed package: %s%s%s\n", ColorDim, ColorRed, result.packageName+devLabel, ColorReset)
-				os.Exit(1)
-			}
-		}
-		completed++
+	failedDeps := c.runParallelInstall(projectName, []installGroup{
+		{title: "Dependencies", packages: deps},
+		{title: "Dev Dependencies", packages: devDeps, isDev: true},
+	}, useBun, policies, strict)
+
+	// In strict mode, stop on the first error
+	if strict && len(failedDeps) > 0 {
+		return failedDeps, installError("installation failed in strict mode (failed package: %s)", failedDeps[0])
 	}
 
 	// Final summary
-	fmt.Printf("\n")
+	printf("\n")
 	if len(failedDeps) > 0 {
-		fmt.Printf("%s⚠ Installation completed with warnings%s\n", ColorYellow, ColorReset)
-		fmt.Printf("%s├─ Failed: %d/%d packages%s\n", ColorDim, len(failedDeps), totalDeps, ColorReset)
+		printf("%s⚠ Installation completed with warnings%s\n", ColorYellow, ColorReset)
+		printf("%s├─ Failed: %d/%d packages%s\n", ColorDim, len(failedDeps), totalDeps, ColorReset)
 		for i, dep := range failedDeps {
 			prefix := "├─"
 			if i == len(failedDeps)-1 {
 				prefix = "└─"
 			}
-			fmt.Printf("%s%s ✗ %s%s\n", ColorDim, prefix, dep, ColorReset)
+			printf("%s%s ✗ %s%s\n", ColorDim, prefix, dep, ColorReset)
 		}
 	} else {
-		fmt.Printf("%s✨ All dependencies installed successfully!%s\n", ColorGreen, ColorReset)
-		fmt.Printf("%s└─ %d/%d packages%s\n", ColorDim, totalDeps, totalDeps, ColorReset)
+		writeInstallStamp(projectName)
+		printf("%s✨ All dependencies installed successfully!%s\n", ColorGreen, ColorReset)
+		printf("%s└─ %d/%d packages%s\n", ColorDim, totalDeps, totalDeps, ColorReset)
 	}
+
+	return failedDeps, nil
 }
 
 // installBun attempts to install Bun
@@ -764,143 +868,34 @@ func (c *CLITool) installBun() bool {
 	return err == nil
 }
 
-// installSingleDependency installs a single package with inline progress
-func (c *CLITool) installSingleDependency(projectName, dep string, isDev, useBun bool, current, total int, failedDeps *[]string, isLast, isDevSection bool) {
-	// Prepare command
-	var cmd *exec.Cmd
-	
-	// Special case: nquickdev needs npm for postinstall scripts (Bun ignores them)
-	if useBun && dep == "nquickdev" {
-		if isDev {
-			cmd = exec.Command("npm", "install", "--save-dev", dep)
-		} else {
-			cmd = exec.Command("npm", "install", dep)
-		}
-	} else if useBun {
-		if isDev {
-			cmd = exec.Command("bun", "add", "-d", dep)
-		} else {
-			cmd = exec.Command("bun", "add", dep)
-		}
-	} else {
-		if isDev {
-			cmd = exec.Command("npm", "install", "--save-dev", dep)
-		} else {
-			cmd = exec.Command("npm", "install", dep)
-		}
-	}
-	cmd.Dir = projectName
-
-	// Tree branch characters
-	branch := "├─"
-	if isLast {
-		branch = "└─"
-	}
-
-	// Progress indicator
-	progress := fmt.Sprintf("[%d/%d]", current, total)
-
-	// Show inline progress with spinner
-	stop := c.showTreeSpinner(branch, dep, progress, isDev)
-
-	var stdout, stderr bytes.Buffer
-	if useBun {
-		// Capture Bun output to filter it
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
-	} else {
-		// Hide npm output
-		cmd.Stdout = ioutil.Discard
-		cmd.Stderr = &stderr
-	}
-
-	err := cmd.Run()
-	
-	// Stop spinner
-	c.clearInlineSpinner(stop)
-
-	// Display result
-	devLabel := ""
-	if isDev {
-		devLabel = " %s(dev)%s"
-		devLabel = fmt.Sprintf(devLabel, ColorDim, ColorReset)
-	}
-
-	if err != nil {
-		fmt.Printf("\r\033[K%s   %s %s%s%s %s✗%s %s%s\n", 
-			ColorDim, branch, progress, ColorReset, ColorRed, ColorRed, ColorReset, dep, devLabel)
-		
-		if !useBun {
-			outputStr := stderr.String()
-			if strings.Contains(outputStr, "404") || strings.Contains(outputStr, "Not Found") {
-				subBranch := "├─"
-				if isLast {
-					subBranch = "   "
-				}
-				fmt.Printf("%s   %s %s→ Package not found%s\n", ColorDim, subBranch, ColorYellow, ColorReset)
-			}
-		}
-		*failedDeps = append(*failedDeps, dep+devLabel)
-	} else {
-		fmt.Printf("\r\033[K%s   %s %s%s%s %s✓%s %s%s\n", 
-			ColorDim, branch, progress, ColorReset, ColorGreen, ColorGreen, ColorReset, dep, devLabel)
-		
-		// Show Bun details on next line if available
-		if useBun {
-			c.displayBunDetails(&stdout, &stderr, isLast)
-		}
-	}
-}
-
-// showInlineSpinner shows a loading spinner on the current line
-func (c *CLITool) showInlineSpinner(message string) chan bool {
-	stop := make(chan bool)
-	go func() {
-		spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-		i := 0
-		for {
-			select {
-			case <-stop:
-				return
-			case <-time.After(80 * time.Millisecond):
-				fmt.Printf("\r  %s%s%s %s", ColorCyan, spinner[i%len(spinner)], ColorReset, message)
-				i++
-			}
-		}
-	}()
-	return stop
-}
-
-// clearInlineSpinner clears the inline spinner line
-func (c *CLITool) clearInlineSpinner(stop chan bool) {
-	stop <- true
-	time.Sleep(10 * time.Millisecond)
-	fmt.Printf("\r\033[K")
-}
-
 // displayProjectConfig displays project configuration in tree format
 func (c *CLITool) displayProjectConfig(config ProjectConfig) {
-	fmt.Printf("%s┌─ Project Configuration%s\n", ColorBold, ColorReset)
-	fmt.Printf("%s│%s\n", ColorDim, ColorReset)
-	fmt.Printf("%s├─%s %sName:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.Name)
+	printf("%s┌─ Project Configuration%s\n", ColorBold, ColorReset)
+	printf("%s│%s\n", ColorDim, ColorReset)
+	printf("%s├─%s %sName:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.Name)
 	
 	if config.Description != "" {
-		fmt.Printf("%s├─%s %sDescription:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.Description)
+		printf("%s├─%s %sDescription:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.Description)
 	}
 	
 	langName := "TypeScript"
 	if config.Language == "js" {
 		langName = "JavaScript"
 	}
-	fmt.Printf("%s├─%s %sLanguage:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, langName)
-	fmt.Printf("%s├─%s %sPort:%s %d\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.Port)
-	fmt.Printf("%s├─%s %sVersion:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.Version)
-	fmt.Printf("%s├─%s %sApp Alias:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.AppAlias)
-	fmt.Printf("%s├─%s %sAuthor:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.Author)
+	printf("%s├─%s %sLanguage:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, langName)
+	printf("%s├─%s %sPort:%s %d\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.Port)
+	printf("%s├─%s %sVersion:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.Version)
+	printf("%s├─%s %sApp Alias:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.AppAlias)
+	printf("%s├─%s %sAuthor:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.Author)
+	printf("%s├─%s %sPackage manager:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, packageManagerName(config.Mode))
+	printf("%s├─%s %sEnvironment:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.Env)
+	if config.License != "" {
+		printf("%s├─%s %sLicense:%s %s\n", ColorDim, ColorReset, ColorCyan, ColorReset, config.License)
+	}
 	
 	// Features
 	if config.WithAuth || config.WithUpload || config.WithMulti {
-		fmt.Printf("%s└─%s %sFeatures:%s\n", ColorDim, ColorReset, ColorCyan, ColorReset)
+		printf("%s└─%s %sFeatures:%s\n", ColorDim, ColorReset, ColorCyan, ColorReset)
 		features := []string{}
 		if config.WithAuth {
 			features = append(features, "Authentication")
@@ -914,119 +909,12 @@ func (c *CLITool) displayProjectConfig(config ProjectConfig) {
 		
 		for i, feature := range features {
 			if i == len(features)-1 {
-				fmt.Printf("   %s└─%s %s\n", ColorDim, ColorReset, feature)
+				printf("   %s└─%s %s\n", ColorDim, ColorReset, feature)
 			} else {
-				fmt.Printf("   %s├─%s %s\n", ColorDim, ColorReset, feature)
+				printf("   %s├─%s %s\n", ColorDim, ColorReset, feature)
 			}
 		}
 	} else {
-		fmt.Printf("%s└─%s %sFeatures:%s None\n", ColorDim, ColorReset, ColorCyan, ColorReset)
-	}
-}
-
-// displayFilteredBunOutput filters and displays Bun output
-func (c *CLITool) displayFilteredBunOutput(stdout, stderr *bytes.Buffer, dep, progress, devLabel string, hasError bool) {
-	output := stdout.String() + stderr.String()
-	lines := strings.Split(output, "\n")
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		
-		// Skip "bun add vX.X.X (XXXXX)" lines - keep only the hash
-		if strings.HasPrefix(line, "bun add v") {
-			// Extract hash from format "bun add v1.3.3 (274e01c7)"
-			if idx := strings.Index(line, "("); idx != -1 {
-				if endIdx := strings.Index(line[idx:], ")"); endIdx != -1 {
-					hash := line[idx+1 : idx+endIdx]
-					fmt.Printf("\r\033[K  %s[%s]%s %s%s%s\n", ColorDim, hash, ColorReset, ColorDim, progress, ColorReset)
-				}
-			}
-			continue
-		}
-		
-		// Display other lines with proper formatting
-		if strings.Contains(line, "installed") {
-			// Format: "installed package@version"
-			fmt.Printf("\r\033[K  %s%s%s %s✓ Installed%s %s%s\n", 
-				ColorGreen, progress, ColorReset, ColorGreen, ColorReset, dep, devLabel)
-		} else if strings.Contains(line, "packages installed") {
-			// Show packages count
-			fmt.Printf("  %s%s%s\n", ColorDim, line, ColorReset)
-		} else if strings.Contains(line, "done") || strings.Contains(line, "ms]") {
-			// Show timing info in dim color
-			fmt.Printf("  %s%s%s\n", ColorDim, line, ColorReset)
-		} else if !strings.HasPrefix(line, "bun add") && !strings.Contains(line, ".env") {
-			// Show other relevant lines
-			fmt.Printf("  %s%s%s\n", ColorDim, line, ColorReset)
-		}
-	}
-	
-	if hasError {
-		fmt.Printf("\r  %s%s%s %s✗ Failed to install %s%s%s\n", 
-			ColorRed, progress, ColorReset, ColorRed, dep, devLabel, ColorReset)
+		printf("%s└─%s %sFeatures:%s None\n", ColorDim, ColorReset, ColorCyan, ColorReset)
 	}
 }
-
-// displayBunDetails displays filtered Bun output details
-func (c *CLITool) displayBunDetails(stdout, stderr *bytes.Buffer, isLast bool) {
-	output := stdout.String() + stderr.String()
-	lines := strings.Split(output, "\n")
-	
-	indent := "       "
-	if isLast {
-		indent = "       "
-	}
-	
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		
-		// Extract hash from "bun add vX.X.X (XXXXX)"
-		if strings.HasPrefix(line, "bun add v") {
-			if idx := strings.Index(line, "("); idx != -1 {
-				if endIdx := strings.Index(line[idx:], ")"); endIdx != -1 {
-					hash := line[idx+1 : idx+endIdx]
-					fmt.Printf("%s%s[%s]%s\n", indent, ColorDim, hash, ColorReset)
-				}
-			}
-			continue
-		}
-		
-		// Show package count and timing
-		if strings.Contains(line, "packages installed") || strings.Contains(line, "done") {
-			fmt.Printf("%s%s%s%s\n", indent, ColorDim, line, ColorReset)
-		}
-	}
-}
-
-// showTreeSpinner shows a spinner for tree-style installation
-func (c *CLITool) showTreeSpinner(branch, dep string, progress string, isDev bool) chan bool {
-	stop := make(chan bool)
-	devLabel := ""
-	if isDev {
-		devLabel = fmt.Sprintf(" %s(dev)%s", ColorDim, ColorReset)
-	}
-	
-	go func() {
-		spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-		i := 0
-		for {
-			select {
-			case <-stop:
-				return
-			case <-time.After(80 * time.Millisecond):
-				fmt.Printf("\r%s   %s %s%s %s%s%s %s%s", 
-					ColorDim, branch, progress, ColorReset,
-					ColorCyan, spinner[i%len(spinner)], ColorReset,
-					dep, devLabel)
-				i++
-			}
-		}
-	}()
-	return stop
-}
\ No newline at end of file
@@ -0,0 +1,356 @@
+package modules
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Option is one choice of a select list
+type Option struct {
+	Label string // Shown to the user
+	Value string // Returned to the caller
+	Hint  string // Optional dimmed description
+}
+
+// Prompter asks questions on the terminal
+// Select lists use arrow keys when the terminal supports raw mode (switched with stty),
+// otherwise every question degrades to a numbered, line-based prompt
+type Prompter struct {
+	reader *bufio.Reader
+	raw    bool // Arrow-key selection is available
+}
+
+// NewPrompter creates a prompter reading from stdin
+func NewPrompter() *Prompter {
+	return &Prompter{reader: bufio.NewReader(os.Stdin), raw: rawModeAvailable()}
+}
+
+// Interactive reports whether a person is answering (stdin is a terminal)
+func (p *Prompter) Interactive() bool {
+	return isTerminal(os.Stdin)
+}
+
+// rawModeAvailable reports whether arrow-key selection can be used
+func rawModeAvailable() bool {
+	if runtime.GOOS == "windows" || !isTerminal(os.Stdin) || !console.Interactive {
+		return false
+	}
+	_, err := exec.LookPath("stty")
+	return err == nil
+}
+
+// stty runs stty on the terminal attached to stdin
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// enterRawMode switches the terminal to unbuffered input without echo and returns a restore function
+// Signals are disabled too, so Ctrl+C is read as a key and the terminal is always restored
+func enterRawMode() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		return nil, err
+	}
+	promptf("\033[?25l") // Hide the cursor
+	return func() {
+		stty(saved)
+		promptf("\033[?25h")
+	}, nil
+}
+
+// Keys understood by select lists
+type promptKey int
+
+const (
+	keyOther promptKey = iota
+	keyUp
+	keyDown
+	keyEnter
+	keySpace
+	keyInterrupt
+)
+
+// readKey reads one key press in raw mode
+func (p *Prompter) readKey() (promptKey, error) {
+	b, err := p.reader.ReadByte()
+	if err != nil {
+		return keyInterrupt, err
+	}
+	switch b {
+	case 3, 4: // Ctrl+C, Ctrl+D
+		return keyInterrupt, nil
+	case '\r', '\n':
+		return keyEnter, nil
+	case ' ':
+		return keySpace, nil
+	case 'k':
+		return keyUp, nil
+	case 'j':
+		return keyDown, nil
+	case 27: // Escape sequence: ESC [ A / ESC O A
+		next, err := p.reader.ReadByte()
+		if err != nil || (next != '[' && next != 'O') {
+			return keyOther, err
+		}
+		arrow, err := p.reader.ReadByte()
+		if err != nil {
+			return keyOther, err
+		}
+		switch arrow {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		}
+	}
+	return keyOther, nil
+}
+
+// readLine reads one answer in line mode; io.EOF means stdin is closed
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", io.EOF
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Input asks for a free-text value and re-prompts until validate accepts it
+// validate returns the normalized value; an empty answer selects defaultValue
+func (p *Prompter) Input(label, defaultValue string, validate func(string) (string, error)) (string, error) {
+	for {
+		if defaultValue != "" {
+			promptf("%s%s%s %s(%s)%s: ", ColorCyan, label, ColorReset, ColorDim, defaultValue, ColorReset)
+		} else {
+			promptf("%s%s:%s ", ColorCyan, label, ColorReset)
+		}
+
+		answer, err := p.readLine()
+		closed := err == io.EOF
+		if closed {
+			promptf("\n")
+		}
+		if answer == "" {
+			answer = defaultValue
+		}
+		if validate == nil {
+			return answer, nil
+		}
+		value, err := validate(answer)
+		if err == nil {
+			return value, nil
+		}
+		if closed {
+			return "", usageError("invalid answer for %s: %v", strings.ToLower(label), err).
+				WithHint("Pass the value as a flag when stdin is not interactive (see 'xypcli init --help')")
+		}
+		promptf("  %s✗ %v%s\n", ColorRed, err, ColorReset)
+	}
+}
+
+// Select asks for one option and returns its index
+func (p *Prompter) Select(label string, options []Option, defaultIndex int) (int, error) {
+	if p.raw {
+		if restore, err := enterRawMode(); err == nil {
+			defer restore()
+			return p.rawSelect(label, options, defaultIndex, nil)
+		}
+		p.raw = false
+	}
+	return p.lineSelect(label, options, defaultIndex)
+}
+
+// MultiSelect asks for any number of options; selected holds the initial state
+func (p *Prompter) MultiSelect(label string, options []Option, selected []bool) ([]bool, error) {
+	checked := append([]bool{}, selected...)
+	if p.raw {
+		if restore, err := enterRawMode(); err == nil {
+			defer restore()
+			_, err := p.rawSelect(label, options, 0, checked)
+			return checked, err
+		}
+		p.raw = false
+	}
+	return p.lineMultiSelect(label, options, checked)
+}
+
+// rawSelect runs an arrow-key select list; with checked != nil it is a multi-select
+func (p *Prompter) rawSelect(label string, options []Option, cursor int, checked []bool) (int, error) {
+	help := "↑/↓ to move, enter to select"
+	if checked != nil {
+		help = "↑/↓ to move, space to toggle, enter to confirm"
+	}
+
+	lines := 0
+	render := func() {
+		if lines > 0 {
+			promptf("\033[%dA\r\033[J", lines)
+		}
+		promptf("%s?%s %s%s%s %s%s%s\n", ColorGreen, ColorReset, ColorBold, label, ColorReset, ColorDim, help, ColorReset)
+		for i, option := range options {
+			pointer, color := "  ", ""
+			if i == cursor {
+				pointer, color = ColorCyan+"❯ ", ColorCyan
+			}
+			box := ""
+			if checked != nil {
+				box = "◯ "
+				if checked[i] {
+					box = ColorGreen + "◉ " + color
+				}
+			}
+			hint := ""
+			if option.Hint != "" {
+				hint = " " + ColorDim + option.Hint
+			}
+			promptf("  %s%s%s%s%s\n", pointer, box, option.Label, hint, ColorReset)
+		}
+		lines = len(options) + 1
+	}
+
+	render()
+	for {
+		key, err := p.readKey()
+		switch {
+		case key == keyInterrupt:
+			promptf("\033[%dA\r\033[J", lines)
+			if err != nil && err != io.EOF {
+				return 0, failureError(err, "failed to read from the terminal")
+			}
+			return 0, interruptedError()
+		case key == keyUp:
+			cursor = (cursor - 1 + len(options)) % len(options)
+		case key == keyDown:
+			cursor = (cursor + 1) % len(options)
+		case key == keySpace && checked != nil:
+			checked[cursor] = !checked[cursor]
+		case key == keyEnter:
+			promptf("\033[%dA\r\033[J", lines)
+			answer := options[cursor].Label
+			if checked != nil {
+				answer = selectedLabels(options, checked)
+			}
+			promptf("%s✔%s %s: %s%s%s\n", ColorGreen, ColorReset, label, ColorCyan, answer, ColorReset)
+			return cursor, nil
+		default:
+			continue
+		}
+		render()
+	}
+}
+
+// lineSelect asks for one option by number, label or value
+func (p *Prompter) lineSelect(label string, options []Option, defaultIndex int) (int, error) {
+	promptf("%s%s:%s\n", ColorCyan, label, ColorReset)
+	for i, option := range options {
+		marker := ""
+		if i == defaultIndex {
+			marker = " " + ColorDim + "(default)" + ColorReset
+		}
+		promptf("  %s%d)%s %s%s\n", ColorCyan, i+1, ColorReset, option.Label, marker)
+	}
+	for {
+		promptf("%sChoose [1-%d]:%s ", ColorBold, len(options), ColorReset)
+		answer, err := p.readLine()
+		if err == io.EOF {
+			promptf("\n")
+			return defaultIndex, nil
+		}
+		if answer == "" {
+			return defaultIndex, nil
+		}
+		if index, ok := matchOption(options, answer); ok {
+			return index, nil
+		}
+		promptf("  %s✗ Please enter a number between 1 and %d%s\n", ColorRed, len(options), ColorReset)
+	}
+}
+
+// lineMultiSelect asks for a comma-separated list of options
+func (p *Prompter) lineMultiSelect(label string, options []Option, checked []bool) ([]bool, error) {
+	promptf("%s%s:%s\n", ColorCyan, label, ColorReset)
+	for i, option := range options {
+		box := "[ ]"
+		if checked[i] {
+			box = "[x]"
+		}
+		promptf("  %s%d)%s %s %s\n", ColorCyan, i+1, ColorReset, box, option.Label)
+	}
+	for {
+		promptf("%sChoose (comma-separated, 'none', enter to keep):%s ", ColorBold, ColorReset)
+		answer, err := p.readLine()
+		if err == io.EOF {
+			promptf("\n")
+			return checked, nil
+		}
+		if answer == "" {
+			return checked, nil
+		}
+		if strings.EqualFold(answer, "none") {
+			return make([]bool, len(options)), nil
+		}
+
+		result := make([]bool, len(options))
+		valid := true
+		for _, part := range strings.Split(answer, ",") {
+			index, ok := matchOption(options, strings.TrimSpace(part))
+			if !ok {
+				promptf("  %s✗ Unknown choice '%s'%s\n", ColorRed, strings.TrimSpace(part), ColorReset)
+				valid = false
+				break
+			}
+			result[index] = true
+		}
+		if valid {
+			return result, nil
+		}
+	}
+}
+
+// matchOption finds an option by 1-based number, value or label (case-insensitive)
+func matchOption(options []Option, answer string) (int, bool) {
+	if number, err := strconv.Atoi(answer); err == nil {
+		return number - 1, number >= 1 && number <= len(options)
+	}
+	for i, option := range options {
+		if strings.EqualFold(answer, option.Value) || strings.EqualFold(answer, option.Label) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// selectedLabels joins the labels of the checked options
+func selectedLabels(options []Option, checked []bool) string {
+	labels := []string{}
+	for i, option := range options {
+		if checked[i] {
+			labels = append(labels, option.Label)
+		}
+	}
+	if len(labels) == 0 {
+		return "None"
+	}
+	return strings.Join(labels, ", ")
+}
+
+// optionIndex returns the index of the option with the given value (or 0)
+func optionIndex(options []Option, value string) int {
+	for i, option := range options {
+		if option.Value == value {
+			return i
+		}
+	}
+	return 0
+}
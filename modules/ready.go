@@ -0,0 +1,169 @@
+package modules
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HealthPath is the health endpoint of the XyPriss templates
+const HealthPath = "/health"
+
+// DefaultWaitTimeout is how long start waits for the server to become ready
+const DefaultWaitTimeout = 60 * time.Second
+
+// readyPollInterval is the delay between two readiness probes
+const readyPollInterval = 250 * time.Millisecond
+
+// Readiness probe results
+const (
+	probeNotReady  = iota
+	probeHealthy   // The health endpoint answered 2xx
+	probeListening // Any other answer but 503, or a TCP connection
+)
+
+// parseWaitTimeout parses --wait-timeout: a Go duration ("90s", "2m") or a number of seconds
+func parseWaitTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return DefaultWaitTimeout, nil
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, usageError("invalid value for --wait-timeout: '%s' (expected e.g. 30s, 2m or a number of seconds)", value)
+	}
+	return duration, nil
+}
+
+// probeServer checks once whether the server on port is ready
+// The health endpoint is tried first: 2xx is healthy, 503 means still starting, and any
+// other status (404 without a health route, 401 behind auth, a redirect...) still proves
+// the server is listening. A server that does not speak HTTP counts as ready as soon as
+// it accepts TCP connections
+func probeServer(port int) int {
+	client := http.Client{
+		Timeout: time.Second,
+		// A redirect (e.g. to a login page) is an answer; following it could leave the server
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	response, err := client.Get(fmt.Sprintf("http://127.0.0.1:%d%s", port, HealthPath))
+	if err == nil {
+		response.Body.Close()
+		switch {
+		case response.StatusCode >= 200 && response.StatusCode < 300:
+			return probeHealthy
+		case response.StatusCode == http.StatusServiceUnavailable:
+			return probeNotReady
+		}
+		return probeListening
+	}
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", port), time.Second)
+	if err != nil {
+		return probeNotReady
+	}
+	conn.Close()
+	return probeListening
+}
+
+// waitForServer polls the server until it is ready, it exits or timeout expires
+// Returns the last probe result; exited is closed when the server process ends
+func waitForServer(port int, timeout time.Duration, exited <-chan struct{}) (int, error) {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+	for {
+		if result := probeServer(port); result != probeNotReady {
+			return result, nil
+		}
+		select {
+		case <-exited:
+			return probeNotReady, failureError(nil, "development server exited before it was ready")
+		case <-deadline:
+			return probeNotReady, failureError(nil, "development server was not ready on port %d after %s", port, timeout).
+				WithHint("Check the server output above, or raise the limit with --wait-timeout")
+		case <-ticker.C:
+		}
+	}
+}
+
+// lanAddress returns the first private IPv4 address of this machine, or ""
+func lanAddress() string {
+	addresses, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	for _, address := range addresses {
+		if ipNet, ok := address.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil && ipNet.IP.IsPrivate() {
+			return ipNet.IP.String()
+		}
+	}
+	return ""
+}
+
+// serverURLs lists the banner rows of a ready server (label, URL) in display order
+func serverURLs(port int, healthy bool, appURLs map[string]string) []helpRow {
+	local := fmt.Sprintf("http://localhost:%d", port)
+	rows := []helpRow{{"Local", local}}
+	if lan := lanAddress(); lan != "" {
+		rows = append(rows, helpRow{"Network", fmt.Sprintf("http://%s:%d", lan, port)})
+	}
+	if healthy {
+		rows = append(rows, helpRow{"Health", local + HealthPath})
+	}
+
+	names := []string{}
+	for name := range appURLs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		url := appURLs[name]
+		if strings.HasPrefix(url, "/") {
+			url = local + url
+		}
+		rows = append(rows, helpRow{name, url})
+	}
+	return rows
+}
+
+// printReadyBanner prints the URLs of a ready server
+func printReadyBanner(rows []helpRow, elapsed time.Duration) {
+	printf("\n%s✓ Server ready in %.1fs%s\n", ColorGreen, elapsed.Seconds(), ColorReset)
+	width := 0
+	for _, row := range rows {
+		if len(row.name) > width {
+			width = len(row.name)
+		}
+	}
+	for i, row := range rows {
+		prefix := "├─"
+		if i == len(rows)-1 {
+			prefix = "└─"
+		}
+		label := row.name + ":" + strings.Repeat(" ", width-len(row.name))
+		printf("%s%s%s %s%s%s %s\n", ColorDim, prefix, ColorReset, ColorCyan, label, ColorReset, row.description)
+	}
+	printf("\n")
+}
+
+// announceReady prints the banner of a ready server and emits server.ready
+func (c *CLITool) announceReady(port int, healthy bool, startedAt time.Time) {
+	rows := serverURLs(port, healthy, readSysConfig(".").AppURLs)
+	printReadyBanner(rows, time.Since(startedAt))
+	urls := map[string]string{}
+	for _, row := range rows {
+		urls[row.name] = row.description
+	}
+	c.events.Emit(EventServerReady, map[string]interface{}{
+		"port":       port,
+		"healthy":    healthy,
+		"urls":       urls,
+		"durationMs": time.Since(startedAt).Milliseconds(),
+	})
+}
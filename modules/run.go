@@ -0,0 +1,605 @@
+package modules
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RunDir holds the PID, state and log files of background servers, relative to the project
+const RunDir = ".xypcli/run"
+
+// detachedEnv carries the run name to the background copy of "xypcli start --detach"
+const detachedEnv = SettingsEnvPrefix + "DETACHED"
+
+// Log rotation: a log larger than maxLogSize is moved to <name>.log.1, keeping maxLogFiles old files
+const (
+	maxLogSize    = 10 << 20
+	maxLogFiles   = 3
+	logTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// runState describes a background server; it is written to <RunDir>/<name>.json
+type runState struct {
+	Name      string    `json:"name"`
+	PID       int       `json:"pid"` // PID of the supervising xypcli process
+	Port      int       `json:"port,omitempty"`
+	Command   string    `json:"command"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// runNamePattern matches characters that are not allowed in run names
+var runNamePattern = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// runName returns the name of the background server of a project (its package name)
+func runName(pkg packageJSON) string {
+	name := runNamePattern.ReplaceAllString(strings.TrimPrefix(pkg.Name, "@"), "-")
+	if name == "" {
+		cwd, _ := os.Getwd()
+		name = runNamePattern.ReplaceAllString(filepath.Base(cwd), "-")
+	}
+	return name
+}
+
+// runPath returns the path of a run file (extension "pid", "json" or "log")
+func runPath(name, extension string) string {
+	return filepath.Join(RunDir, name+"."+extension)
+}
+
+// writeRunState records a running background server
+func writeRunState(state runState) error {
+	if err := os.MkdirAll(RunDir, 0755); err != nil {
+		return failureError(err, "failed to create %s", RunDir)
+	}
+	// Keep run files out of version control
+	ignore := filepath.Join(RunDir, ".gitignore")
+	if !fileExists(ignore) {
+		os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+	data, _ := json.MarshalIndent(state, "", "  ")
+	if err := os.WriteFile(runPath(state.Name, "json"), append(data, '\n'), 0644); err != nil {
+		return failureError(err, "failed to write %s", runPath(state.Name, "json"))
+	}
+	return os.WriteFile(runPath(state.Name, "pid"), []byte(strconv.Itoa(state.PID)+"\n"), 0644)
+}
+
+// removeRunState forgets a background server (its log is kept)
+func removeRunState(name string) {
+	os.Remove(runPath(name, "pid"))
+	os.Remove(runPath(name, "json"))
+}
+
+// readRuns returns the recorded background servers, sorted by name
+func readRuns() []runState {
+	paths, _ := filepath.Glob(filepath.Join(RunDir, "*.json"))
+	runs := []runState{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var state runState
+		if json.Unmarshal(data, &state) == nil && state.PID > 0 {
+			runs = append(runs, state)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Name < runs[j].Name })
+	return runs
+}
+
+// findRun returns the recorded server with the given name
+func findRun(name string) (runState, bool) {
+	for _, run := range readRuns() {
+		if run.Name == name {
+			return run, true
+		}
+	}
+	return runState{}, false
+}
+
+// runNames completes the names of background servers
+func runNames() []string {
+	names := []string{}
+	for _, run := range readRuns() {
+		names = append(names, run.Name)
+	}
+	return names
+}
+
+// checkNotRunning fails when a background server with this name is running, and forgets stale ones
+func checkNotRunning(name string) error {
+	if run, ok := findRun(name); ok && processAlive(run.PID) {
+		return environmentError("%s is already running in the background (pid %d)", name, run.PID).
+			WithHint("Stop it with 'xypcli stop %s', or see 'xypcli status'", name)
+	}
+	removeRunState(name)
+	return nil
+}
+
+// startDetached starts "xypcli start" again in the background, detached from the terminal,
+// and waits until the server is ready. port is the resolved port (0 if unknown)
+func (c *CLITool) startDetached(name string, opts StartOptions, port int, overridePort bool) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return failureError(err, "failed to locate the xypcli executable")
+	}
+	args := []string{"start", "--no-color", "--wait-timeout", opts.WaitTimeout.String()}
+	if opts.Entry != "" {
+		args = append(args, "--entry", opts.Entry)
+	}
+	if opts.Mode != "" {
+		args = append(args, "--mode", opts.Mode)
+	}
+	if opts.Env != "" {
+		args = append(args, "--env", opts.Env)
+	}
+	if overridePort {
+		args = append(args, "--port", strconv.Itoa(port))
+	}
+
+	if err := os.MkdirAll(RunDir, 0755); err != nil {
+		return failureError(err, "failed to create %s", RunDir)
+	}
+	logPath := runPath(name, "log")
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return failureError(err, "failed to open %s", logPath)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(executable, args...)
+	cmd.Env = append(os.Environ(), detachedEnv+"="+name)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	detachProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		return failureError(err, "failed to start the background server")
+	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+
+	printf("%s🌙 Starting %s in the background (pid %d)...%s\n", ColorBlue, name, cmd.Process.Pid, ColorReset)
+	startedAt := time.Now()
+	if port != 0 {
+		result, err := waitForServer(port, opts.WaitTimeout, exited)
+		if err != nil {
+			select {
+			case <-exited:
+			default:
+				terminateProcess(cmd.Process.Pid)
+			}
+			printLogTail(logPath, 15)
+			return err
+		}
+		c.announceReady(port, result == probeHealthy, startedAt)
+	}
+
+	c.events.Emit(EventServerDetached, map[string]interface{}{
+		"name": name,
+		"pid":  cmd.Process.Pid,
+		"port": port,
+		"log":  logPath,
+	})
+	resultf("%s✓ %s is running in the background (pid %d)%s\n", ColorGreen, name, cmd.Process.Pid, ColorReset)
+	printf("%s├─ Logs:   xypcli logs -f %s%s\n", ColorDim, name, ColorReset)
+	printf("%s├─ Status: xypcli status%s\n", ColorDim, ColorReset)
+	printf("%s└─ Stop:   xypcli stop %s%s\n", ColorDim, name, ColorReset)
+	return nil
+}
+
+// printLogTail prints the last lines of a log, to explain why a background server failed
+func printLogTail(path string, lines int) {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return
+	}
+	all := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+	printf("%sLast lines of %s:%s\n", ColorDim, path, ColorReset)
+	for _, line := range all {
+		printf("  %s\n", line)
+	}
+}
+
+// attachRunLog sends everything the background server and the CLI print to the
+// rotating log of the run. The returned function flushes the log and restores stdout
+func attachRunLog(name string) (func(), error) {
+	log, err := openRotatingLog(runPath(name, "log"))
+	if err != nil {
+		return nil, err
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		log.Close()
+		return nil, failureError(err, "failed to create the log pipe")
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = writer, writer
+
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(log, reader)
+		close(copied)
+	}()
+	return func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		writer.Close()
+		<-copied
+		log.Close()
+	}, nil
+}
+
+// rotatingLog is a log file that prefixes every line with a timestamp and rotates by size
+type rotatingLog struct {
+	path        string
+	file        *os.File
+	size        int64
+	atLineStart bool
+}
+
+// openRotatingLog opens a log for appending
+func openRotatingLog(path string) (*rotatingLog, error) {
+	log := &rotatingLog{path: path, atLineStart: true}
+	if err := log.open(); err != nil {
+		return nil, failureError(err, "failed to open %s", path)
+	}
+	return log, nil
+}
+
+func (l *rotatingLog) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, _ := file.Stat()
+	l.file, l.size = file, info.Size()
+	return nil
+}
+
+// rotate moves <log> to <log>.1, <log>.1 to <log>.2 and so on, dropping the oldest
+func (l *rotatingLog) rotate() error {
+	l.file.Close()
+	os.Remove(fmt.Sprintf("%s.%d", l.path, maxLogFiles))
+	for i := maxLogFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	os.Rename(l.path, l.path+".1")
+	return l.open()
+}
+
+// Write timestamps the start of each line and rotates the file when it is full
+func (l *rotatingLog) Write(p []byte) (int, error) {
+	var b bytes.Buffer
+	for rest := p; len(rest) > 0; {
+		if l.atLineStart {
+			b.WriteString(time.Now().UTC().Format(logTimeFormat) + " ")
+			l.atLineStart = false
+		}
+		end := bytes.IndexByte(rest, '\n')
+		if end < 0 {
+			b.Write(rest)
+			break
+		}
+		b.Write(rest[:end+1])
+		rest = rest[end+1:]
+		l.atLineStart = true
+	}
+
+	if l.size > 0 && l.size+int64(b.Len()) > maxLogSize {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := l.file.Write(b.Bytes())
+	l.size += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the log file
+func (l *rotatingLog) Close() error {
+	return l.file.Close()
+}
+
+// StopServers stops background servers: the named one, every one (all) or the only one running
+func (c *CLITool) StopServers(name string, all bool) error {
+	runs := readRuns()
+	targets := []runState{}
+	switch {
+	case all:
+		targets = runs
+	case name != "":
+		run, ok := findRun(name)
+		if !ok {
+			return environmentError("no background server named '%s'", name).
+				WithHint("See 'xypcli status' for the running servers")
+		}
+		targets = append(targets, run)
+	case len(runs) > 1:
+		return usageError("several background servers are running (%s)", strings.Join(runNames(), ", ")).
+			WithHint("Name the one to stop, or pass --all")
+	default:
+		targets = runs
+	}
+	if len(targets) == 0 {
+		return environmentError("no background server is running in this project").
+			WithHint("Start one with 'xypcli start --detach'")
+	}
+
+	// Servers of a multi-service project are stopped in reverse start order
+	sort.Slice(targets, func(i, j int) bool { return targets[i].StartedAt.After(targets[j].StartedAt) })
+	timeout := loadSupervisorConfig(".").GracefulTimeout + 5*time.Second
+	for _, run := range targets {
+		if !processAlive(run.PID) {
+			printf("  %s→ %s was not running (stale pid %d)%s\n", ColorDim, run.Name, run.PID, ColorReset)
+			removeRunState(run.Name)
+			continue
+		}
+		printf("%s⏹  Stopping %s (pid %d)...%s\n", ColorYellow, run.Name, run.PID, ColorReset)
+		terminateProcess(run.PID)
+		forced := !waitForExit(run.PID, timeout)
+		if forced {
+			printf("  %s⚠ %s did not stop within %s, killing it%s\n", ColorYellow, run.Name, timeout, ColorReset)
+			killProcess(run.PID)
+			waitForExit(run.PID, 2*time.Second)
+		}
+		removeRunState(run.Name)
+		c.events.Emit(EventServerStopped, map[string]interface{}{
+			"name":   run.Name,
+			"pid":    run.PID,
+			"forced": forced,
+		})
+		resultf("%s✓ Stopped %s%s\n", ColorGreen, run.Name, ColorReset)
+	}
+	return nil
+}
+
+// waitForExit polls a process until it exits; returns false on timeout
+func waitForExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !processAlive(pid) {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return !processAlive(pid)
+}
+
+// ServerStatus prints the background servers of the project with their health and resources
+func (c *CLITool) ServerStatus() error {
+	runs := readRuns()
+	if len(runs) == 0 {
+		resultf("%sNo background server is running in this project%s\n", ColorDim, ColorReset)
+		return nil
+	}
+
+	rows := [][]string{{"NAME", "PID", "PORT", "UPTIME", "HEALTH", "MEMORY"}}
+	for _, run := range runs {
+		alive := processAlive(run.PID)
+		health, uptime, memory := "stopped", "-", "-"
+		port := "-"
+		if run.Port != 0 {
+			port = strconv.Itoa(run.Port)
+		}
+		var rss int64
+		if alive {
+			uptime = formatUptime(time.Since(run.StartedAt))
+			health = "running"
+			if run.Port != 0 {
+				health = map[int]string{probeHealthy: "healthy", probeListening: "listening", probeNotReady: "not ready"}[probeServer(run.Port)]
+			}
+			if rss = processTreeMemory(run.PID); rss > 0 {
+				memory = formatBytes(rss)
+			}
+		}
+		rows = append(rows, []string{run.Name, strconv.Itoa(run.PID), port, uptime, health, memory})
+
+		c.events.Emit(EventServerStatus, map[string]interface{}{
+			"name":        run.Name,
+			"pid":         run.PID,
+			"port":        run.Port,
+			"running":     alive,
+			"health":      health,
+			"uptimeMs":    time.Since(run.StartedAt).Milliseconds(),
+			"memoryBytes": rss,
+		})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	for r, row := range rows {
+		line := ""
+		for i, cell := range row {
+			line += cell + strings.Repeat(" ", widths[i]-len(cell)+2)
+		}
+		line = strings.TrimRight(line, " ")
+		switch {
+		case r == 0:
+			resultf("%s%s%s\n", ColorBold, line, ColorReset)
+		case row[4] == "healthy" || row[4] == "listening" || row[4] == "running":
+			resultf("%s\n", line)
+		default:
+			resultf("%s%s%s\n", ColorYellow, line, ColorReset)
+		}
+	}
+	return nil
+}
+
+// formatUptime renders a duration as e.g. "2h13m" or "45s"
+func formatUptime(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd%02dh", int(d.Hours())/24, int(d.Hours())%24)
+}
+
+// formatBytes renders a size as e.g. "84.2 MB"
+func formatBytes(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	}
+	return fmt.Sprintf("%d KB", size>>10)
+}
+
+// parseSince parses --since: a duration ("10m", "2h") or a timestamp (RFC 3339 or 2006-01-02)
+func parseSince(value string) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, usageError("invalid value for --since: '%s' (expected e.g. 10m, 2h or 2024-05-01T12:00:00Z)", value)
+}
+
+// ShowLogs prints the log of a background server, optionally following it
+func (c *CLITool) ShowLogs(name string, follow bool, since string) error {
+	if name == "" {
+		runs := readRuns()
+		switch {
+		case len(runs) == 1:
+			name = runs[0].Name
+		case len(runs) > 1:
+			return usageError("several background servers are running (%s)", strings.Join(runNames(), ", ")).
+				WithHint("Name the server whose logs to show")
+		default:
+			pkg, _ := readPackageJSON(".")
+			name = runName(pkg)
+		}
+	}
+	path := runPath(name, "log")
+	if !fileExists(path) {
+		return environmentError("no log for '%s' in %s", name, RunDir).
+			WithHint("Logs are written by 'xypcli start --detach'")
+	}
+
+	var from time.Time
+	if since != "" {
+		var err error
+		if from, err = parseSince(since); err != nil {
+			return err
+		}
+	}
+
+	// Rotated files are read oldest first
+	files := []string{}
+	for i := maxLogFiles; i >= 1; i-- {
+		if rotated := fmt.Sprintf("%s.%d", path, i); fileExists(rotated) {
+			files = append(files, rotated)
+		}
+	}
+	files = append(files, path)
+
+	filter := &logFilter{from: from, keep: from.IsZero()}
+	var offset int64
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		filter.print(data)
+		if file == path {
+			offset = int64(len(data))
+		}
+	}
+	if !follow {
+		return nil
+	}
+
+	// Follow the log until Ctrl+C, reopening it after a rotation
+	c.handleSignals()
+	current, _ := os.Stat(path)
+	for !c.interrupted() {
+		time.Sleep(250 * time.Millisecond)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if !os.SameFile(info, current) || info.Size() < offset {
+			current, offset = info, 0
+		}
+		if info.Size() == offset {
+			continue
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		file.Seek(offset, io.SeekStart)
+		data, _ := io.ReadAll(file)
+		file.Close()
+		offset += int64(len(data))
+		filter.print(data)
+	}
+	return nil
+}
+
+// logFilter prints log lines written at or after from
+// Lines without a timestamp follow the decision made for the previous line
+type logFilter struct {
+	from    time.Time
+	keep    bool
+	partial []byte // Incomplete last line of the previous chunk
+}
+
+func (f *logFilter) print(data []byte) {
+	data = append(f.partial, data...)
+	f.partial = nil
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), maxLogSize)
+	complete := bytes.HasSuffix(data, []byte("\n"))
+	lines := []string{}
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if !complete && len(lines) > 0 {
+		f.partial = []byte(lines[len(lines)-1])
+		lines = lines[:len(lines)-1]
+	}
+	for _, line := range lines {
+		if !f.from.IsZero() {
+			if stamp, _, ok := strings.Cut(line, " "); ok {
+				if t, err := time.Parse(logTimeFormat, stamp); err == nil {
+					f.keep = !t.Before(f.from)
+				}
+			}
+		}
+		if f.keep {
+			resultf("%s\n", line)
+		}
+	}
+}
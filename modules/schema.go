@@ -0,0 +1,401 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ConfigSchemaFile is the JSON Schema written by "xypcli config schema"
+const ConfigSchemaFile = "xypriss.config.schema.json"
+
+// xyprissConfig is the typed content of xypriss.config.json
+type xyprissConfig struct {
+	Schema   string       `json:"$schema,omitempty"`
+	Sys      sysConfig    `json:"__sys__"`
+	Services []serviceDef `json:"services,omitempty"`
+}
+
+// sysConfig is the __sys__ section of xypriss.config.json, written by init and read by the CLI
+type sysConfig struct {
+	Version     string            `json:"__version__,omitempty"`
+	Author      string            `json:"__author__,omitempty"`
+	Name        string            `json:"__name__,omitempty"`
+	Description string            `json:"__description__,omitempty"`
+	Alias       string            `json:"__alias__,omitempty"`
+	Port        int               `json:"__port__,omitempty"`
+	PortUpper   int               `json:"__PORT__,omitempty"` // Legacy alias of __port__, kept for older XyPriss versions
+	Env         string            `json:"__env__,omitempty"`
+	AppURLs     map[string]string `json:"__app_urls__,omitempty"`
+}
+
+// readXyPrissConfig reads the xypriss.config.json in dir
+// Fields with a wrong type are left empty; 'xypcli config validate' reports them
+func readXyPrissConfig(dir string) (xyprissConfig, error) {
+	var config xyprissConfig
+	data, err := os.ReadFile(filepath.Join(dir, XyPrissConfigFile))
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		if _, ok := err.(*json.UnmarshalTypeError); !ok {
+			return config, err
+		}
+	}
+	return config, nil
+}
+
+// readSysConfig reads the __sys__ section of the xypriss.config.json in dir
+// A missing or invalid file gives an empty section
+func readSysConfig(dir string) sysConfig {
+	config, _ := readXyPrissConfig(dir)
+	return config.Sys
+}
+
+// schemaNode describes a JSON value; it drives both 'config validate' and the emitted JSON Schema
+type schemaNode struct {
+	Type        string // "object", "array", "string", "integer" or "boolean"
+	Description string
+	Properties  []schemaProperty // Known keys of an object, in display order
+	Values      *schemaNode      // Values of an object with free-form keys
+	Items       *schemaNode      // Items of an array
+	Enum        []string
+	Minimum     int
+	Maximum     int // Checked when not 0
+}
+
+// schemaProperty is a key of an object
+type schemaProperty struct {
+	Name     string
+	Node     *schemaNode
+	Required bool
+}
+
+// portSchema describes a TCP port
+func portSchema(description string) *schemaNode {
+	return &schemaNode{Type: "integer", Description: description, Minimum: 1, Maximum: 65535}
+}
+
+// xyprissConfigSchema describes xypriss.config.json
+var xyprissConfigSchema = &schemaNode{
+	Type:        "object",
+	Description: "XyPriss project configuration",
+	Properties: []schemaProperty{
+		{Name: "$schema", Node: &schemaNode{Type: "string", Description: "JSON Schema of this file, for editors"}},
+		{Name: "__sys__", Required: true, Node: &schemaNode{
+			Type:        "object",
+			Description: "System variables of the application, written by 'xypcli init'",
+			Properties: []schemaProperty{
+				{Name: "__version__", Node: &schemaNode{Type: "string", Description: "Application version"}},
+				{Name: "__author__", Node: &schemaNode{Type: "string", Description: "Author of the application"}},
+				{Name: "__name__", Node: &schemaNode{Type: "string", Description: "Application name"}},
+				{Name: "__description__", Node: &schemaNode{Type: "string", Description: "Application description"}},
+				{Name: "__alias__", Node: &schemaNode{Type: "string", Description: "Short application alias"}},
+				{Name: "__port__", Required: true, Node: portSchema("Port of the server")},
+				{Name: "__PORT__", Node: portSchema("Legacy alias of __port__; must be equal to it")},
+				{Name: "__env__", Node: &schemaNode{Type: "string", Description: "Environment selected with --env", Enum: environments}},
+				{Name: "__app_urls__", Node: &schemaNode{
+					Type:        "object",
+					Description: "Extra URLs printed when the server is ready (paths starting with / are relative to the server)",
+					Values:      &schemaNode{Type: "string"},
+				}},
+			},
+		}},
+		{Name: "services", Node: &schemaNode{
+			Type:        "array",
+			Description: "Services started by 'xypcli start --all'",
+			Items: &schemaNode{
+				Type: "object",
+				Properties: []schemaProperty{
+					{Name: "name", Required: true, Node: &schemaNode{Type: "string", Description: "Name of the service"}},
+					{Name: "dir", Node: &schemaNode{Type: "string", Description: "Directory of the service, relative to the project"}},
+					{Name: "command", Node: &schemaNode{Type: "string", Description: "Shell command; empty runs the service like 'xypcli start'"}},
+					{Name: "port", Node: portSchema("Port passed as PORT and used for readiness")},
+					{Name: "env", Node: &schemaNode{Type: "object", Description: "Extra environment variables", Values: &schemaNode{Type: "string"}}},
+					{Name: "dependsOn", Node: &schemaNode{Type: "array", Description: "Services that must be ready first", Items: &schemaNode{Type: "string"}}},
+				},
+			},
+		}},
+	},
+}
+
+// configProblem is one finding of 'xypcli config validate'
+type configProblem struct {
+	Pointer  string `json:"pointer"` // JSON pointer of the value (RFC 6901)
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Severities of configuration problems
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// jsonPointer appends a key to a JSON pointer, escaping it
+func jsonPointer(pointer, key string) string {
+	return pointer + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// jsonTypeName returns the JSON type of a decoded value
+func jsonTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// validate checks a decoded value against the node and appends the problems found
+func (node *schemaNode) validate(value interface{}, pointer string, problems *[]configProblem) {
+	report := func(severity, format string, a ...interface{}) {
+		*problems = append(*problems, configProblem{Pointer: orDefault(pointer, "/"), Severity: severity, Message: fmt.Sprintf(format, a...)})
+	}
+	if actual := jsonTypeName(value); actual != node.Type {
+		report(SeverityError, "expected %s, got %s", node.Type, actual)
+		return
+	}
+
+	switch node.Type {
+	case "integer":
+		number := int(value.(float64))
+		if node.Maximum != 0 && (number < node.Minimum || number > node.Maximum) {
+			report(SeverityError, "%d is out of range (%d-%d)", number, node.Minimum, node.Maximum)
+		}
+	case "string":
+		if len(node.Enum) > 0 && !containsString(node.Enum, value.(string)) {
+			report(SeverityWarning, "'%s' is not one of %s", value, strings.Join(node.Enum, ", "))
+		}
+	case "array":
+		for i, item := range value.([]interface{}) {
+			node.Items.validate(item, fmt.Sprintf("%s/%d", pointer, i), problems)
+		}
+	case "object":
+		object := value.(map[string]interface{})
+		known := map[string]bool{}
+		for _, property := range node.Properties {
+			known[property.Name] = true
+			if child, ok := object[property.Name]; ok {
+				property.Node.validate(child, jsonPointer(pointer, property.Name), problems)
+			} else if property.Required {
+				report(SeverityError, "missing required key \"%s\"", property.Name)
+			}
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			switch {
+			case known[key]:
+			case node.Values != nil:
+				node.Values.validate(object[key], jsonPointer(pointer, key), problems)
+			default:
+				*problems = append(*problems, configProblem{Pointer: jsonPointer(pointer, key), Severity: SeverityWarning, Message: "unknown key"})
+			}
+		}
+	}
+}
+
+// jsonSchema converts the node to a JSON Schema (draft 2020-12) document
+func (node *schemaNode) jsonSchema() map[string]interface{} {
+	schema := map[string]interface{}{"type": node.Type}
+	if node.Description != "" {
+		schema["description"] = node.Description
+	}
+	if len(node.Enum) > 0 {
+		schema["enum"] = node.Enum
+	}
+	if node.Maximum != 0 {
+		schema["minimum"], schema["maximum"] = node.Minimum, node.Maximum
+	}
+	if node.Items != nil {
+		schema["items"] = node.Items.jsonSchema()
+	}
+	if node.Type == "object" {
+		if len(node.Properties) > 0 {
+			properties := map[string]interface{}{}
+			required := []string{}
+			for _, property := range node.Properties {
+				properties[property.Name] = property.Node.jsonSchema()
+				if property.Required {
+					required = append(required, property.Name)
+				}
+			}
+			schema["properties"] = properties
+			if len(required) > 0 {
+				schema["required"] = required
+			}
+		}
+		if node.Values != nil {
+			schema["additionalProperties"] = node.Values.jsonSchema()
+		}
+	}
+	return schema
+}
+
+// checkConfigConsistency compares xypriss.config.json with package.json, .env and itself
+func checkConfigConsistency(dir string, config xyprissConfig) []configProblem {
+	problems := []configProblem{}
+	add := func(pointer, severity, format string, a ...interface{}) {
+		problems = append(problems, configProblem{Pointer: pointer, Severity: severity, Message: fmt.Sprintf(format, a...)})
+	}
+	sys := config.Sys
+
+	if sys.Port != 0 && sys.PortUpper != 0 && sys.Port != sys.PortUpper {
+		add("/__sys__/__PORT__", SeverityError, "%d differs from __port__ (%d)", sys.PortUpper, sys.Port)
+	}
+	if env, err := readDotenvFile(filepath.Join(dir, ".env")); err == nil {
+		if value, ok := env.lookup("PORT"); ok && sys.Port != 0 && value != fmt.Sprint(sys.Port) {
+			add("/__sys__/__port__", SeverityWarning, "%d differs from PORT=%s in .env", sys.Port, value)
+		}
+	}
+
+	if pkg, err := readPackageJSONFields(dir); err == nil {
+		if sys.Name != "" && pkg["name"] != "" && pkg["name"] != strings.ToLower(strings.ReplaceAll(sys.Name, " ", "-")) {
+			add("/__sys__/__name__", SeverityWarning, "\"%s\" does not match the package.json name \"%s\"", sys.Name, pkg["name"])
+		}
+		if sys.Version != "" && pkg["version"] != "" && pkg["version"] != sys.Version {
+			add("/__sys__/__version__", SeverityWarning, "%s differs from the package.json version %s", sys.Version, pkg["version"])
+		}
+		if sys.Description != "" && pkg["description"] != "" && pkg["description"] != sys.Description {
+			add("/__sys__/__description__", SeverityWarning, "differs from the package.json description")
+		}
+	}
+
+	if len(config.Services) > 0 {
+		if err := validateServices(config.Services, XyPrissConfigFile); err != nil {
+			add("/services", SeverityError, "%v", err)
+		} else if _, err := orderServices(config.Services); err != nil {
+			add("/services", SeverityError, "%v", err)
+		}
+	}
+	return problems
+}
+
+// readPackageJSONFields returns the string fields of the package.json in dir
+func readPackageJSONFields(dir string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	fields := map[string]string{}
+	for key, value := range raw {
+		if text, ok := value.(string); ok {
+			fields[key] = text
+		}
+	}
+	return fields, nil
+}
+
+// jsonSyntaxLocation returns the line and column of a byte offset
+func jsonSyntaxLocation(data []byte, offset int64) (int, int) {
+	line, column := 1, 1
+	for i := 0; i < int(offset) && i < len(data); i++ {
+		if data[i] == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
+
+// ConfigValidate checks xypriss.config.json against its schema and the rest of the project
+// Errors fail the command; warnings only do with strict
+func (c *CLITool) ConfigValidate(file string, strict bool) error {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return environmentError("no %s found", file).
+			WithHint("Run it in a XyPriss project directory")
+	}
+	if err != nil {
+		return environmentError("failed to read %s: %v", file, err)
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		if syntax, ok := err.(*json.SyntaxError); ok {
+			line, column := jsonSyntaxLocation(data, syntax.Offset)
+			return environmentError("invalid JSON in %s at line %d, column %d: %v", file, line, column, err)
+		}
+		return environmentError("invalid JSON in %s: %v", file, err)
+	}
+
+	problems := []configProblem{}
+	xyprissConfigSchema.validate(raw, "", &problems)
+	config := xyprissConfig{}
+	json.Unmarshal(data, &config)
+	problems = append(problems, checkConfigConsistency(filepath.Dir(file), config)...)
+
+	errors, warnings := 0, 0
+	for _, problem := range problems {
+		if problem.Severity == SeverityError {
+			errors++
+			printf("%s✗ %s%s: %s\n", ColorRed, problem.Pointer, ColorReset, problem.Message)
+		} else {
+			warnings++
+			printf("%s⚠ %s%s: %s\n", ColorYellow, problem.Pointer, ColorReset, problem.Message)
+		}
+	}
+	c.events.Emit(EventConfigValidated, map[string]interface{}{
+		"file":     file,
+		"errors":   errors,
+		"warnings": warnings,
+		"problems": problems,
+	})
+
+	if errors > 0 || (strict && warnings > 0) {
+		return environmentError("found %d error(s) and %d warning(s) in %s", errors, warnings, file).
+			WithHint("Run 'xypcli config schema' to let your editor check the file as you type")
+	}
+	if warnings > 0 {
+		printf("\n%s✓ %s is valid, with %d warning(s)%s\n", ColorGreen, file, warnings, ColorReset)
+		return nil
+	}
+	printf("%s✓ %s is valid%s\n", ColorGreen, file, ColorReset)
+	return nil
+}
+
+// ConfigSchema writes the JSON Schema of xypriss.config.json to out ("-" prints it)
+func (c *CLITool) ConfigSchema(out string) error {
+	schema := xyprissConfigSchema.jsonSchema()
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = XyPrissConfigFile
+	data, _ := json.MarshalIndent(schema, "", "  ")
+	data = append(data, '\n')
+
+	if out == "-" {
+		resultf("%s", data)
+		return nil
+	}
+	if err := os.WriteFile(out, data, 0644); err != nil {
+		return failureError(err, "failed to write %s", out)
+	}
+	printf("%s✓ JSON Schema written to %s%s\n", ColorGreen, out, ColorReset)
+	if config, err := readXyPrissConfig(filepath.Dir(out)); err == nil && config.Schema == "" {
+		printf("  %s→ Add \"$schema\": \"./%s\" to %s so that editors use it%s\n", ColorDim, filepath.Base(out), XyPrissConfigFile, ColorReset)
+	}
+	return nil
+}
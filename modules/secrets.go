@@ -0,0 +1,312 @@
+package modules
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// SecretsKeyFile holds the key of the project's encrypted .env files; it must never be committed
+const SecretsKeyFile = ".xypcli/secrets.key"
+
+// secretsKeyEnv holds the key where there is no key file (e.g. in CI)
+const secretsKeyEnv = SettingsEnvPrefix + "SECRETS_KEY"
+
+// secretsHeader is the first line of an encrypted file
+const secretsHeader = "# xypcli secrets v1 (NaCl secretbox). Change with 'xypcli secrets set' or 'xypcli secrets edit'."
+
+// secretsNonceSize is the size of the random nonce stored before each sealed file
+const secretsNonceSize = 24
+
+// secretsPath returns the encrypted file of env (.env.enc without an environment)
+func secretsPath(env string) string {
+	if env == "" {
+		return ".env.enc"
+	}
+	return ".env." + env + ".enc"
+}
+
+// loadSecretsKey returns the key from XYPCLI_SECRETS_KEY or the key file of dir, and where it comes from
+func loadSecretsKey(dir string) ([]byte, string, error) {
+	encoded, source := os.Getenv(secretsKeyEnv), secretsKeyEnv
+	if encoded == "" {
+		data, err := os.ReadFile(filepath.Join(dir, SecretsKeyFile))
+		if err != nil {
+			return nil, "", environmentError("no secrets key found").
+				WithHint("Put the key in %s or %s, or run 'xypcli secrets init' to create one", SecretsKeyFile, secretsKeyEnv)
+		}
+		encoded, source = string(data), SecretsKeyFile
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != 32 {
+		return nil, "", environmentError("invalid secrets key in %s (expected 32 bytes, base64-encoded)", source)
+	}
+	return key, source, nil
+}
+
+// writeSecretsKey stores a new key in the key file of dir and keeps it out of git
+func writeSecretsKey(dir string, key []byte) error {
+	path := filepath.Join(dir, SecretsKeyFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return failureError(err, "failed to create %s", filepath.Dir(path))
+	}
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		return failureError(err, "failed to write %s", path)
+	}
+	// WriteFile keeps the mode of a file that already existed (e.g. a damaged key being replaced)
+	if err := os.Chmod(path, 0600); err != nil {
+		return failureError(err, "failed to make %s readable by its owner only", path)
+	}
+
+	gitignore := filepath.Join(dir, ".gitignore")
+	data, _ := os.ReadFile(gitignore)
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == SecretsKeyFile || strings.TrimSpace(line) == "/"+SecretsKeyFile {
+			return nil
+		}
+	}
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	data = append(data, []byte(SecretsKeyFile+"\n")...)
+	if err := os.WriteFile(gitignore, data, 0644); err != nil {
+		return failureError(err, "failed to update .gitignore")
+	}
+	return nil
+}
+
+// randomSecret returns size random bytes, base64url-encoded (e.g. for JWT signing)
+func randomSecret(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buffer), nil
+}
+
+// encryptSecrets seals dotenv content with NaCl secretbox (XSalsa20-Poly1305)
+func encryptSecrets(key, plaintext []byte) ([]byte, error) {
+	var nonce [secretsNonceSize]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	var secretKey [32]byte
+	copy(secretKey[:], key)
+	sealed := secretbox.Seal(nonce[:], plaintext, &nonce, &secretKey)
+	return []byte(secretsHeader + "\n" + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// decryptSecrets opens the content of an encrypted file
+func decryptSecrets(key, data []byte, path string) ([]byte, error) {
+	lines := strings.SplitN(strings.TrimSpace(string(data)), "\n", 2)
+	if len(lines) != 2 || lines[0] != secretsHeader {
+		return nil, environmentError("%s is not a xypcli secrets file", path)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sealed) < secretsNonceSize+secretbox.Overhead {
+		return nil, environmentError("%s is damaged", path)
+	}
+	var nonce [secretsNonceSize]byte
+	copy(nonce[:], sealed)
+	var secretKey [32]byte
+	copy(secretKey[:], key)
+	plaintext, ok := secretbox.Open(nil, sealed[secretsNonceSize:], &nonce, &secretKey)
+	if !ok {
+		return nil, environmentError("cannot decrypt %s: wrong key or modified file", path).
+			WithHint("Check %s or %s", SecretsKeyFile, secretsKeyEnv)
+	}
+	return plaintext, nil
+}
+
+// readSecretsFile decrypts an encrypted dotenv file in memory
+func readSecretsFile(path string, key []byte) (*dotenvFile, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, environmentError("%s does not exist", path).
+			WithHint("Create it with 'xypcli secrets init'")
+	}
+	if err != nil {
+		return nil, environmentError("failed to read %s: %v", path, err)
+	}
+	plaintext, err := decryptSecrets(key, data, path)
+	if err != nil {
+		return nil, err
+	}
+	return &dotenvFile{path: path, exists: true, entries: parseDotenvEntries(string(plaintext))}, nil
+}
+
+// writeSecretsFile encrypts a dotenv document to its path
+func writeSecretsFile(file *dotenvFile, key []byte) error {
+	data, err := encryptSecrets(key, []byte(file.content()))
+	if err != nil {
+		return failureError(err, "failed to encrypt %s", file.path)
+	}
+	if err := os.WriteFile(file.path, data, 0644); err != nil {
+		return failureError(err, "failed to write %s", file.path)
+	}
+	return nil
+}
+
+// SecretsInit creates the key (unless one is configured) and an empty encrypted file
+func (c *CLITool) SecretsInit(env string) error {
+	if err := requireProject(); err != nil {
+		return err
+	}
+	path := secretsPath(env)
+
+	key, source, err := loadSecretsKey(".")
+	if err != nil {
+		for _, name := range []string{".env.enc", path} {
+			if fileExists(name) {
+				return environmentError("%s exists but no key was found to decrypt it", name).
+					WithHint("Ask a teammate for the key and put it in %s or %s", SecretsKeyFile, secretsKeyEnv)
+			}
+		}
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return failureError(err, "failed to generate a key")
+		}
+		if err := writeSecretsKey(".", key); err != nil {
+			return err
+		}
+		printf("%s✓ Key created in %s%s %s(added to .gitignore)%s\n", ColorGreen, SecretsKeyFile, ColorReset, ColorDim, ColorReset)
+		printf("  %s→ Share it with your team through a password manager, and set %s in CI%s\n", ColorDim, secretsKeyEnv, ColorReset)
+	} else {
+		printf("%s→ Using the key from %s%s\n", ColorDim, source, ColorReset)
+	}
+
+	if fileExists(path) {
+		printf("%s%s already exists%s\n", ColorDim, path, ColorReset)
+		return nil
+	}
+	if err := writeSecretsFile(&dotenvFile{path: path}, key); err != nil {
+		return err
+	}
+	printf("%s✓ %s created%s %s(safe to commit)%s\n", ColorGreen, path, ColorReset, ColorDim, ColorReset)
+	return nil
+}
+
+// openSecrets loads the key and decrypts the encrypted file of env
+func openSecrets(env string) (*dotenvFile, []byte, error) {
+	key, _, err := loadSecretsKey(".")
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := readSecretsFile(secretsPath(env), key)
+	return file, key, err
+}
+
+// SecretsSet stores a variable in the encrypted file and lists its key in .env.example
+func (c *CLITool) SecretsSet(key, value, env string) error {
+	if err := validateEnvKey(key); err != nil {
+		return err
+	}
+	file, secretsKey, err := openSecrets(env)
+	if err != nil {
+		return err
+	}
+	file.set(key, value)
+	if err := writeSecretsFile(file, secretsKey); err != nil {
+		return err
+	}
+	printf("%s✓ %s stored%s %s(%s)%s\n", ColorGreen, key, ColorReset, ColorDim, file.path, ColorReset)
+	if added, err := syncEnvExample(".", key, true); err != nil {
+		return err
+	} else if added {
+		printf("  %s→ Added %s to %s%s\n", ColorDim, key, EnvExampleFile, ColorReset)
+	}
+	return nil
+}
+
+// SecretsGet prints a variable of the encrypted file
+func (c *CLITool) SecretsGet(key, env string) error {
+	file, _, err := openSecrets(env)
+	if err != nil {
+		return err
+	}
+	value, ok := file.lookup(key)
+	if !ok {
+		return environmentError("%s is not set in %s", key, file.path)
+	}
+	resultf("%s\n", value)
+	return nil
+}
+
+// SecretsEdit opens the decrypted file in $VISUAL / $EDITOR and encrypts the result
+// The plaintext only exists in a private temporary file while the editor runs
+func (c *CLITool) SecretsEdit(env string) error {
+	file, key, err := openSecrets(env)
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp("", "xypcli-secrets-*.env")
+	if err != nil {
+		return failureError(err, "failed to create a temporary file")
+	}
+	defer os.Remove(temp.Name())
+	original := file.content()
+	if err := temp.Chmod(0600); err != nil {
+		temp.Close()
+		return failureError(err, "failed to restrict the permissions of %s", temp.Name())
+	}
+	_, err = temp.WriteString(original)
+	temp.Close()
+	if err != nil {
+		return failureError(err, "failed to write a temporary file")
+	}
+
+	if err := c.runEditor(temp.Name()); err != nil {
+		return err
+	}
+	edited, err := os.ReadFile(temp.Name())
+	if err != nil {
+		return failureError(err, "failed to read the edited file")
+	}
+	if string(edited) == original {
+		printf("%sNo changes%s\n", ColorDim, ColorReset)
+		return nil
+	}
+
+	updated := &dotenvFile{path: file.path, exists: true, entries: parseDotenvEntries(string(edited))}
+	for _, name := range updated.keys() {
+		if err := validateEnvKey(name); err != nil {
+			return usageError("invalid variable name '%s', %s was not changed", name, file.path)
+		}
+	}
+	if err := writeSecretsFile(updated, key); err != nil {
+		return err
+	}
+	printf("%s✓ %s saved%s %s(%d variables)%s\n", ColorGreen, file.path, ColorReset, ColorDim, len(updated.keys()), ColorReset)
+	for _, name := range updated.keys() {
+		if _, err := syncEnvExample(".", name, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SecretsDecrypt prints the decrypted file, or writes it to out (readable by its owner only)
+func (c *CLITool) SecretsDecrypt(env, out string) error {
+	file, _, err := openSecrets(env)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		resultf("%s", file.content())
+		return nil
+	}
+	if err := os.WriteFile(out, []byte(file.content()), 0600); err != nil {
+		return failureError(err, "failed to write %s", out)
+	}
+	// WriteFile keeps the mode of a file that already existed
+	if err := os.Chmod(out, 0600); err != nil {
+		return failureError(err, "failed to make %s readable by its owner only", out)
+	}
+	printf("%s✓ Decrypted %s to %s%s %s(do not commit it)%s\n", ColorGreen, file.path, out, ColorReset, ColorDim, ColorReset)
+	return nil
+}
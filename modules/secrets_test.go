@@ -0,0 +1,179 @@
+package modules
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testSecretsKey returns a fixed 32-byte key filled with b
+func testSecretsKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+// checkEnvironmentError fails unless err is an environment error whose message contains want
+func checkEnvironmentError(t *testing.T, err error, want string) {
+	t.Helper()
+	var cliErr *CLIError
+	if !errors.As(err, &cliErr) || cliErr.Kind != KindEnvironment {
+		t.Fatalf("got %v, want an environment error", err)
+	}
+	if !strings.Contains(cliErr.Message, want) {
+		t.Errorf("got %q, want a message containing %q", cliErr.Message, want)
+	}
+}
+
+func TestSecretsRoundTrip(t *testing.T) {
+	plaintext := []byte("DB_URL=postgres://x\nTOKEN='a b'\n")
+	data, err := encryptSecrets(testSecretsKey(1), plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), secretsHeader+"\n") || bytes.Contains(data, []byte("postgres")) {
+		t.Fatalf("unexpected encrypted content:\n%s", data)
+	}
+	got, err := decryptSecrets(testSecretsKey(1), data, ".env.enc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("got %q, want %q", got, plaintext)
+	}
+
+	// Every encryption uses a new nonce
+	again, _ := encryptSecrets(testSecretsKey(1), plaintext)
+	if bytes.Equal(again, data) {
+		t.Error("two encryptions produced the same content")
+	}
+}
+
+func TestDecryptSecretsErrors(t *testing.T) {
+	data, err := encryptSecrets(testSecretsKey(1), []byte("A=1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitN(strings.TrimSpace(string(data)), "\n", 2)
+	sealed, _ := base64.StdEncoding.DecodeString(lines[1])
+	encode := func(payload []byte) []byte {
+		return []byte(secretsHeader + "\n" + base64.StdEncoding.EncodeToString(payload) + "\n")
+	}
+	tampered := append([]byte{}, sealed...)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name string
+		key  []byte
+		data []byte
+		want string
+	}{
+		{"wrong key", testSecretsKey(2), data, "wrong key or modified file"},
+		{"tampered ciphertext", testSecretsKey(1), encode(tampered), "wrong key or modified file"},
+		{"tampered nonce", testSecretsKey(1), encode(append([]byte{sealed[0] ^ 1}, sealed[1:]...)), "wrong key or modified file"},
+		{"missing header", testSecretsKey(1), []byte(lines[1] + "\n"), "is not a xypcli secrets file"},
+		{"foreign header", testSecretsKey(1), []byte("# other tool v1\n" + lines[1] + "\n"), "is not a xypcli secrets file"},
+		{"plain dotenv file", testSecretsKey(1), []byte("A=1\n"), "is not a xypcli secrets file"},
+		{"truncated payload", testSecretsKey(1), encode(sealed[:secretsNonceSize+4]), "is damaged"},
+		{"not base64", testSecretsKey(1), []byte(secretsHeader + "\n%%%\n"), "is damaged"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := decryptSecrets(test.key, test.data, ".env.enc")
+			checkEnvironmentError(t, err, test.want)
+		})
+	}
+}
+
+func TestLoadSecretsKey(t *testing.T) {
+	key := testSecretsKey(7)
+	encoded := base64.StdEncoding.EncodeToString(key)
+
+	t.Run("from the environment", func(t *testing.T) {
+		t.Setenv(secretsKeyEnv, " "+encoded+"\n")
+		got, source, err := loadSecretsKey(t.TempDir())
+		if err != nil || !bytes.Equal(got, key) || source != secretsKeyEnv {
+			t.Errorf("got %v from %q (%v)", got, source, err)
+		}
+	})
+
+	t.Run("from the key file", func(t *testing.T) {
+		t.Setenv(secretsKeyEnv, "")
+		dir := t.TempDir()
+		if err := writeSecretsKey(dir, key); err != nil {
+			t.Fatal(err)
+		}
+		got, source, err := loadSecretsKey(dir)
+		if err != nil || !bytes.Equal(got, key) || source != SecretsKeyFile {
+			t.Errorf("got %v from %q (%v)", got, source, err)
+		}
+		info, err := os.Stat(filepath.Join(dir, SecretsKeyFile))
+		if err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("key file mode %v (%v), want 0600", info.Mode().Perm(), err)
+		}
+		gitignore, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+		if string(gitignore) != SecretsKeyFile+"\n" {
+			t.Errorf(".gitignore is %q", gitignore)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Setenv(secretsKeyEnv, "")
+		_, _, err := loadSecretsKey(t.TempDir())
+		checkEnvironmentError(t, err, "no secrets key found")
+	})
+
+	for name, value := range map[string]string{
+		"too short":  base64.StdEncoding.EncodeToString(key[:16]),
+		"too long":   base64.StdEncoding.EncodeToString(append(key, 0)),
+		"not base64": "not a key!",
+	} {
+		t.Run("invalid key "+name, func(t *testing.T) {
+			t.Setenv(secretsKeyEnv, value)
+			_, _, err := loadSecretsKey(t.TempDir())
+			checkEnvironmentError(t, err, "invalid secrets key in "+secretsKeyEnv)
+		})
+	}
+}
+
+func TestSecretsDecryptOutIsPrivate(t *testing.T) {
+	disableColors()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	t.Setenv(secretsKeyEnv, base64.StdEncoding.EncodeToString(testSecretsKey(3)))
+	file := &dotenvFile{path: secretsPath(""), entries: parseDotenvEntries("A=1\n")}
+	if err := writeSecretsFile(file, testSecretsKey(3)); err != nil {
+		t.Fatal(err)
+	}
+	// An existing file keeps its mode through WriteFile
+	if err := os.WriteFile(".env.plain", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	captureStdout(t, func() {
+		err = NewCLITool("test").SecretsDecrypt("", ".env.plain")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(".env.plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("got mode %v, want 0600", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(".env.plain"); string(data) != "A=1\n" {
+		t.Errorf("got %q", data)
+	}
+}
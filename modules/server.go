@@ -1,52 +1,364 @@
 package modules
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
-) 
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuickdevConfigFile is the quickdev configuration of a project
+const QuickdevConfigFile = "quickdev.config.json"
+
+// StartOptions holds the options of the start command
+type StartOptions struct {
+	Entry string // Entry file given with --entry (overrides every other source)
+	Mode  string // Package manager given with --mode: "b", "n" or "" (detected)
+	Port  string // Port given with --port: a number, "auto" or "" (from xypriss.config.json)
+
+	WaitTimeout time.Duration // How long to wait for the server to become ready
+	Detach      bool          // Run in the background (see run.go)
+
+	Env      string // Environment given with --env ("" uses __sys__.__env__, NODE_ENV or development)
+	PrintEnv bool   // Print the merged environment and exit
+}
+
+// conventionalEntries are tried when neither package.json nor quickdev.config.json names an entry point
+var conventionalEntries = []string{"src/server.ts", "src/server.js", "src/index.ts", "src/index.js", "server.ts", "server.js", "index.ts", "index.js"}
+
+// startPlan describes how the development server is started
+type startPlan struct {
+	Entry   string   // Entry file, when the CLI runs it directly
+	Source  string   // Where the entry point or script was found
+	Command []string // Program and arguments to run
+}
+
+// entryCandidate is one place checked while resolving the entry point
+type entryCandidate struct {
+	Source string
+	Detail string
+}
+
+// packageJSON holds the package.json fields used by the CLI
+type packageJSON struct {
+	Name            string            `json:"name"`
+	Main            string            `json:"main"`
+	Scripts         map[string]string `json:"scripts"`
+	PackageManager  string            `json:"packageManager"`
+	Type            string            `json:"type"` // "module" for ES modules
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+// readPackageJSON reads package.json from a directory
+func readPackageJSON(dir string) (packageJSON, error) {
+	var pkg packageJSON
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return pkg, err
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return pkg, fmt.Errorf("invalid package.json: %v", err)
+	}
+	return pkg, nil
+}
+
+// readQuickdevScript returns the "script" field of quickdev.config.json, if any
+func readQuickdevScript(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, QuickdevConfigFile))
+	if err != nil {
+		return ""
+	}
+	var config struct {
+		Script string `json:"script"`
+	}
+	json.Unmarshal(data, &config)
+	return config.Script
+}
+
+// scriptEntry returns the file passed with --script in a quickdev command line, if any
+func scriptEntry(script string) string {
+	fields := strings.Fields(script)
+	for i, field := range fields {
+		if field == "--script" && i+1 < len(fields) {
+			return fields[i+1]
+		}
+		if strings.HasPrefix(field, "--script=") {
+			return strings.TrimPrefix(field, "--script=")
+		}
+	}
+	return ""
+}
+
+// fileExists reports whether a regular file exists
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// isTypeScript reports whether an entry file needs a TypeScript runner
+func isTypeScript(entry string) bool {
+	switch filepath.Ext(entry) {
+	case ".ts", ".tsx", ".mts", ".cts":
+		return true
+	}
+	return false
+}
+
+// localBin returns the path of a binary installed in node_modules/.bin, if present
+func localBin(dir, name string) string {
+	path := filepath.Join(dir, "node_modules", ".bin", name)
+	if fileExists(path) {
+		return path
+	}
+	return ""
+}
+
+// runnerFor returns the command that runs an entry file in watch mode
+// quickdev (installed by every XyPriss template) is preferred; otherwise TypeScript
+// runs with bun or tsx and JavaScript with node
+func runnerFor(dir, entry string) []string {
+	if quickdev := localBin(dir, "quickdev"); quickdev != "" {
+		return []string{quickdev, "--script", entry}
+	}
+	if !isTypeScript(entry) {
+		return []string{"node", "--watch", entry}
+	}
+	if _, err := exec.LookPath("bun"); err == nil {
+		return []string{"bun", "--watch", entry}
+	}
+	if tsx := localBin(dir, "tsx"); tsx != "" {
+		return []string{tsx, "watch", entry}
+	}
+	return []string{"npx", "--yes", "tsx", "watch", entry}
+}
+
+// resolveStartPlan decides how to start the server in dir; manager runs the dev script
+// Sources are checked in order: --entry, package.json scripts.dev, the "script" field of
+// quickdev.config.json, package.json main and finally the conventional entry files
+func resolveStartPlan(dir string, opts StartOptions, pkg packageJSON, manager string) (startPlan, []entryCandidate) {
+	checked := []entryCandidate{}
+	entryPlan := func(entry, source string) startPlan {
+		return startPlan{Entry: entry, Source: source, Command: runnerFor(dir, entry)}
+	}
+
+	if opts.Entry != "" {
+		if fileExists(filepath.Join(dir, opts.Entry)) {
+			return entryPlan(opts.Entry, "--entry"), nil
+		}
+		return startPlan{}, []entryCandidate{{"--entry", opts.Entry + " (not found)"}}
+	}
+
+	if dev := pkg.Scripts["dev"]; dev != "" {
+		// A dev script that points at a missing file (e.g. ./src/server.ts in a JS project) is skipped
+		if entry := scriptEntry(dev); entry != "" && !fileExists(filepath.Join(dir, entry)) {
+			checked = append(checked, entryCandidate{"package.json scripts.dev", fmt.Sprintf("%q (%s not found)", dev, entry)})
+		} else {
+			return startPlan{Entry: scriptEntry(dev), Source: "package.json scripts.dev", Command: []string{manager, "run", "dev"}}, nil
+		}
+	} else {
+		checked = append(checked, entryCandidate{"package.json scripts.dev", "not defined"})
+	}
+
+	if script := readQuickdevScript(dir); script != "" {
+		if fileExists(filepath.Join(dir, script)) {
+			return entryPlan(script, QuickdevConfigFile+" script"), checked
+		}
+		checked = append(checked, entryCandidate{QuickdevConfigFile + " script", script + " (not found)"})
+	} else {
+		checked = append(checked, entryCandidate{QuickdevConfigFile + " script", "not defined"})
+	}
+
+	if pkg.Main != "" {
+		if fileExists(filepath.Join(dir, pkg.Main)) {
+			return entryPlan(pkg.Main, "package.json main"), checked
+		}
+		checked = append(checked, entryCandidate{"package.json main", pkg.Main + " (not found)"})
+	} else {
+		checked = append(checked, entryCandidate{"package.json main", "not defined"})
+	}
+
+	for _, entry := range conventionalEntries {
+		if fileExists(filepath.Join(dir, entry)) {
+			return entryPlan(entry, "default entry file"), checked
+		}
+	}
+	checked = append(checked, entryCandidate{"default entry files", strings.Join(conventionalEntries, ", ") + " (none found)"})
+	return startPlan{}, checked
+}
 
 // StartServer starts the XyPriss development server in the current directory
-func (c *CLITool) StartServer() {
-	fmt.Println(XyPrissLogo)
-	fmt.Printf("%s🚀 Starting XyPriss development server...%s\n\n", ColorGreen, ColorReset)
+func (c *CLITool) StartServer(opts StartOptions) error {
+	// The background copy started by --detach writes everything to its run log
+	detachedName := os.Getenv(detachedEnv)
+	if detachedName != "" {
+		closeLog, err := attachRunLog(detachedName)
+		if err != nil {
+			return err
+		}
+		defer closeLog()
+	}
+
+	// --print-env only prints the environment, without the start banner
+	if !opts.PrintEnv {
+		printLogo()
+		printf("%s🚀 Starting XyPriss development server...%s\n\n", ColorGreen, ColorReset)
+	}
 
 	// Check if package.json exists
-	if _, err := os.Stat("package.json"); os.IsNotExist(err) {
-		fmt.Printf("%s❌ No package.json found.%s Are you in a XyPriss project directory?\n", ColorRed, ColorReset)
-		fmt.Printf("   Run %s'xypcli init'%s to create a new project.\n", ColorCyan, ColorReset)
-		return
+	pkg, err := readPackageJSON(".")
+	if os.IsNotExist(err) {
+		return environmentError("no package.json found. Are you in a XyPriss project directory?").
+			WithHint("Run 'xypcli init' to create a new project.")
+	}
+	if err != nil {
+		return environmentError("%v", err)
+	}
+
+	// Merge the .env layers of the selected environment
+	envName, envSource := resolveEnvironment(".", opts.Env)
+	envVars, envFiles, err := loadEnvironment(".", envName)
+	if err != nil {
+		return err
+	}
+	if opts.PrintEnv {
+		c.printEnvironment(envName, envSource, envVars, envFiles, false)
+		return nil
+	}
+	if opts.Env != "" {
+		if err := recordEnvironment(".", opts.Env); err != nil {
+			return err
+		}
+	}
+	printf("  %s→ Environment: %s (from %s)%s\n", ColorDim, envName, envSource, ColorReset)
+	if missing := missingRequiredKeys(".", envVars); len(missing) > 0 {
+		printf("  %s⚠ Missing required variables (from %s): %s%s\n", ColorYellow, EnvExampleFile, strings.Join(missing, ", "), ColorReset)
+	}
+
+	if opts.Detach {
+		if err := checkNotRunning(runName(pkg)); err != nil {
+			return err
+		}
+	}
+
+	// Detect the package manager used for installing and running
+	manager, managerSource, err := c.detectPackageManager(".", pkg, opts.Mode)
+	if err != nil {
+		return err
+	}
+	printf("  %s→ Package manager: %s (from %s)%s\n", ColorDim, manager, managerSource, ColorReset)
+
+	// Resolve the entry point
+	plan, checked := resolveStartPlan(".", opts, pkg, manager)
+	if len(plan.Command) == 0 {
+		printf("%s✗ No entry point found. Checked:%s\n", ColorRed, ColorReset)
+		for i, candidate := range checked {
+			prefix := "├─"
+			if i == len(checked)-1 {
+				prefix = "└─"
+			}
+			printf("  %s%s%s %s: %s\n", ColorDim, prefix, ColorReset, candidate.Source, candidate.Detail)
+		}
+		return environmentError("no entry point found for the development server").
+			WithHint("Pass one with 'xypcli start --entry <file>', or set \"script\" in %s", QuickdevConfigFile)
+	}
+	for _, candidate := range checked {
+		printf("  %s→ Skipped %s: %s%s\n", ColorDim, candidate.Source, candidate.Detail, ColorReset)
 	}
 
-	// Check if src/server.ts exists
-	if _, err := os.Stat("src/server.ts"); os.IsNotExist(err) {
-		fmt.Printf("%s❌ No src/server.ts found.%s Are you in a XyPriss project directory?\n", ColorRed, ColorReset)
-		fmt.Printf("   Run %s'xypcli init'%s to create a new project.\n", ColorCyan, ColorReset)
-		return
+	// Check the port before anything is installed: --port, then __sys__.__port__, then
+	// PORT from the environment layers when the config has no port
+	requestedPort, portSource := opts.Port, "--port"
+	envPort := envPortVar(envVars)
+	switch {
+	case requestedPort == "auto":
+		portSource = "--port auto"
+	case requestedPort != "":
+	case readSysPort(".") == 0 && envPort.Key != "":
+		requestedPort, portSource = envPort.Value, "PORT in "+envPort.Source
+	default:
+		portSource = "__sys__.__port__"
+	}
+	port, overridePort, err := resolveStartPort(".", requestedPort)
+	if err != nil {
+		return err
+	}
+	if envPort.Key != "" && envPort.Value != strconv.Itoa(port) {
+		// The server must listen where readiness is checked, not on the PORT of the env layers
+		overridePort = true
+		hint := " (use --port to change it)"
+		if strings.HasPrefix(portSource, "--port") {
+			hint = ""
+		}
+		printf("  %s→ PORT=%s from %s is ignored, %s wins%s%s\n", ColorDim, envPort.Value, envPort.Source, portSource, hint, ColorReset)
 	}
 
-	// Check if node_modules exists
-	if _, err := os.Stat("node_modules"); os.IsNotExist(err) {
-		fmt.Printf("%s📦 Installing dependencies...%s\n", ColorBlue, ColorReset)
-		installCmd := exec.Command("npm", "install")
+	// Install dependencies when node_modules is missing or older than the lockfile
+	if reason := staleDependencies("."); reason != "" {
+		install := installCommand(manager, c.settings.String("registry"))
+		printf("%s📦 Installing dependencies with %s (%s)...%s\n", ColorBlue, manager, reason, ColorReset)
+		installCmd := exec.Command(install[0], install[1:]...)
 		installCmd.Stdout = os.Stdout
 		installCmd.Stderr = os.Stderr
 		if err := installCmd.Run(); err != nil {
-			fmt.Printf("%s❌ Failed to install dependencies:%s %v\n", ColorRed, ColorReset, err)
-			return
+			if c.interrupted() {
+				return interruptedError()
+			}
+			return newError(KindInstall, err, "failed to install dependencies with %s", manager).
+				WithHint("Run '%s' manually to see the full error", strings.Join(install, " "))
 		}
+		writeInstallStamp(".")
+		// The runner may come from node_modules/.bin, which only exists now
+		if plan.Source != "package.json scripts.dev" {
+			plan.Command = runnerFor(".", plan.Entry)
+		}
+	}
+
+	if opts.Detach {
+		return c.startDetached(runName(pkg), opts, port, overridePort)
 	}
 
 	// Start the server
-	fmt.Printf("%s🔥 Starting development server...%s\n", ColorYellow, ColorReset)
-	fmt.Printf("%sPress Ctrl+C to stop the server%s\n\n", ColorDim, ColorReset)
+	command := strings.Join(plan.Command, " ")
+	printf("%s🔥 Starting development server...%s\n", ColorYellow, ColorReset)
+	if plan.Entry != "" {
+		printf("  %s→ Entry: %s (from %s)%s\n", ColorDim, plan.Entry, plan.Source, ColorReset)
+	}
+	printf("  %s→ Command: %s%s\n", ColorDim, command, ColorReset)
+	if port != 0 {
+		printf("  %s→ Port: %d (from %s)%s\n", ColorDim, port, portSource, ColorReset)
+	}
+	printf("%sPress Ctrl+C to stop the server%s\n\n", ColorDim, ColorReset)
 
-	cmd := exec.Command("npm", "run", "dev")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+	c.events.Emit(EventServerStarting, map[string]interface{}{
+		"command": command,
+		"entry":   plan.Entry,
+		"source":  plan.Source,
+		"manager": manager,
+		"port":    port,
+	})
 
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("\n%s❌ Failed to start server:%s %v\n", ColorRed, ColorReset, err)
+	// The supervisor owns Ctrl+C from now on and forwards it to the server
+	c.handleSignals()
+
+	env := childEnvironment(envVars)
+	if overridePort {
+		// The last PORT entry wins over the one of the env layers
+		env = append(env, fmt.Sprintf("PORT=%d", port))
+	}
+	if detachedName != "" {
+		state := runState{Name: detachedName, PID: os.Getpid(), Port: port, Command: command, StartedAt: time.Now()}
+		if err := writeRunState(state); err != nil {
+			return err
+		}
+		defer removeRunState(detachedName)
+	}
+	if port == 0 {
+		printf("%s⚠ No __sys__.__port__ in %s, readiness is not checked%s\n", ColorYellow, XyPrissConfigFile, ColorReset)
 	}
-}
\ No newline at end of file
+	return c.superviseServer(plan.Command, env, loadSupervisorConfig("."), port, opts.WaitTimeout)
+}
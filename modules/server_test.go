@@ -0,0 +1,134 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveStartPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		entry   string
+		pkg     packageJSON
+		source  string
+		want    string
+		command []string
+	}{
+		{
+			name:   "--entry",
+			files:  []string{"app.js", "src/index.js"},
+			entry:  "app.js",
+			pkg:    packageJSON{Main: "src/index.js"},
+			source: "--entry",
+			want:   "app.js",
+		},
+		{
+			name:    "dev script",
+			files:   []string{"src/server.ts"},
+			pkg:     packageJSON{Main: "src/index.js", Scripts: map[string]string{"dev": "quickdev --script src/server.ts"}},
+			source:  "package.json scripts.dev",
+			want:    "src/server.ts",
+			command: []string{"pnpm", "run", "dev"},
+		},
+		{
+			name:    "dev script without a --script file",
+			pkg:     packageJSON{Scripts: map[string]string{"dev": "vite"}},
+			source:  "package.json scripts.dev",
+			command: []string{"pnpm", "run", "dev"},
+		},
+		{
+			name:   "dev script with a missing target",
+			files:  []string{"src/server.js"},
+			pkg:    packageJSON{Scripts: map[string]string{"dev": "quickdev --script=src/server.ts"}},
+			source: "default entry file",
+			want:   "src/server.js",
+		},
+		{
+			name:   "quickdev script before main",
+			files:  []string{"quickdev.config.json", "app.js", "main.js"},
+			pkg:    packageJSON{Main: "main.js"},
+			source: QuickdevConfigFile + " script",
+			want:   "app.js",
+		},
+		{
+			name:   "main",
+			files:  []string{"main.js", "index.js"},
+			pkg:    packageJSON{Main: "main.js"},
+			source: "package.json main",
+			want:   "main.js",
+		},
+		{
+			name:   "conventional entry file",
+			files:  []string{"index.js", "src/index.ts"},
+			source: "default entry file",
+			want:   "src/index.ts",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range test.files {
+				content := ""
+				if file == "quickdev.config.json" {
+					content = `{"script": "app.js"}`
+				}
+				writeTestFile(t, dir, file, content)
+			}
+			plan, _ := resolveStartPlan(dir, StartOptions{Entry: test.entry}, test.pkg, "pnpm")
+			if plan.Source != test.source || plan.Entry != test.want {
+				t.Errorf("got entry %q from %q, want %q from %q", plan.Entry, plan.Source, test.want, test.source)
+			}
+			if test.command != nil && !reflect.DeepEqual(plan.Command, test.command) {
+				t.Errorf("got command %q, want %q", plan.Command, test.command)
+			}
+			if plan.Entry != "" && test.command == nil && len(plan.Command) == 0 {
+				t.Errorf("no command to run %s", plan.Entry)
+			}
+		})
+	}
+}
+
+func TestResolveStartPlanListsCheckedCandidates(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, QuickdevConfigFile, `{"script": "src/app.ts"}`)
+	pkg := packageJSON{Main: "dist/index.js", Scripts: map[string]string{"dev": "quickdev --script src/server.ts"}}
+
+	plan, checked := resolveStartPlan(dir, StartOptions{}, pkg, "npm")
+	if plan.Source != "" {
+		t.Fatalf("got a plan from %s, want none", plan.Source)
+	}
+	want := []entryCandidate{
+		{"package.json scripts.dev", `"quickdev --script src/server.ts" (src/server.ts not found)`},
+		{QuickdevConfigFile + " script", "src/app.ts (not found)"},
+		{"package.json main", "dist/index.js (not found)"},
+		{"default entry files", "src/server.ts, src/server.js, src/index.ts, src/index.js, server.ts, server.js, index.ts, index.js (none found)"},
+	}
+	if !reflect.DeepEqual(checked, want) {
+		t.Errorf("got candidates\n%q\nwant\n%q", checked, want)
+	}
+
+	_, checked = resolveStartPlan(dir, StartOptions{}, packageJSON{}, "npm")
+	if checked[0].Detail != "not defined" || checked[2].Detail != "not defined" {
+		t.Errorf("undefined sources reported as %q", checked)
+	}
+
+	_, checked = resolveStartPlan(dir, StartOptions{Entry: "missing.js"}, pkg, "npm")
+	if want := []entryCandidate{{"--entry", "missing.js (not found)"}}; !reflect.DeepEqual(checked, want) {
+		t.Errorf("got candidates %q, want %q", checked, want)
+	}
+}
+
+// writeTestFile creates dir/name with content, and its parent directories
+func writeTestFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,355 @@
+package modules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ServicesFile lists the services of a multi-server project (alternative to "services" in xypriss.config.json)
+const ServicesFile = "xypcli.services.json"
+
+// serviceDef is one entry of the services list
+type serviceDef struct {
+	Name      string            `json:"name"`
+	Dir       string            `json:"dir"`       // Directory of the service, relative to the project (default ".")
+	Command   string            `json:"command"`   // Shell command; empty runs the service like 'xypcli start'
+	Port      int               `json:"port"`      // Passed as PORT; 0 uses __sys__.__port__ of the service directory
+	Env       map[string]string `json:"env"`       // Extra environment variables
+	DependsOn []string          `json:"dependsOn"` // Services that must be ready first
+}
+
+// loadServices reads the services of the project in dir and returns them with the file they come from
+// xypcli.services.json wins over the "services" section of xypriss.config.json
+func loadServices(dir string) ([]serviceDef, string, error) {
+	for _, source := range []string{ServicesFile, XyPrissConfigFile} {
+		data, err := os.ReadFile(filepath.Join(dir, source))
+		if err != nil {
+			continue
+		}
+		var file struct {
+			Services []serviceDef `json:"services"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, source, environmentError("invalid services in %s: %v", source, err)
+		}
+		if len(file.Services) > 0 {
+			return file.Services, source, validateServices(file.Services, source)
+		}
+	}
+	return nil, "", environmentError("no services defined in this project").
+		WithHint("Add a \"services\" list to %s or %s", XyPrissConfigFile, ServicesFile)
+}
+
+// validateServices checks names and dependencies
+func validateServices(services []serviceDef, source string) error {
+	names := map[string]bool{}
+	for i, service := range services {
+		if service.Name == "" {
+			return environmentError("service #%d in %s has no name", i+1, source)
+		}
+		if names[service.Name] {
+			return environmentError("service '%s' is defined twice in %s", service.Name, source)
+		}
+		names[service.Name] = true
+	}
+	for _, service := range services {
+		for _, dependency := range service.DependsOn {
+			if !names[dependency] {
+				return environmentError("service '%s' depends on unknown service '%s' in %s", service.Name, dependency, source)
+			}
+		}
+	}
+	return nil
+}
+
+// orderServices sorts services so that every service comes after its dependencies
+// Services keep their declaration order otherwise; a dependency cycle is an error
+func orderServices(services []serviceDef) ([]serviceDef, error) {
+	ordered := []serviceDef{}
+	state := map[string]int{} // 0 = pending, 1 = visiting, 2 = done
+	byName := map[string]serviceDef{}
+	for _, service := range services {
+		byName[service.Name] = service
+	}
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case 1:
+			return environmentError("services have a dependency cycle: %s", strings.Join(append(path, name), " → "))
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		for _, dependency := range byName[name].DependsOn {
+			if err := visit(dependency, append(append([]string{}, path...), name)); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		ordered = append(ordered, byName[name])
+		return nil
+	}
+	for _, service := range services {
+		if err := visit(service.Name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// resolveService returns the command, directory, port and environment of a service
+func (c *CLITool) resolveService(service serviceDef) ([]string, string, int, []string, error) {
+	dir := service.Dir
+	if dir == "" {
+		dir = "."
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, "", 0, nil, environmentError("directory '%s' of service '%s' does not exist", dir, service.Name)
+	}
+
+	// Each service gets the .env layers of its own directory; "env" and "port" of the definition win
+	envName, _ := resolveEnvironment(dir, "")
+	vars, _, err := loadEnvironment(dir, envName)
+	if err != nil {
+		return nil, "", 0, nil, err
+	}
+	env := childEnvironment(vars)
+	for key, value := range service.Env {
+		env = append(env, key+"="+value)
+	}
+	// As for 'xypcli start': __sys__.__port__, then PORT from the env layers
+	port := readSysPort(dir)
+	envPort := envPortVar(vars)
+	if envPort.Key != "" && port == 0 {
+		port, _ = strconv.Atoi(envPort.Value)
+	}
+	if service.Port != 0 {
+		port = service.Port
+	}
+	if port != 0 && (service.Port != 0 || envPort.Value != strconv.Itoa(port)) {
+		env = append(env, fmt.Sprintf("PORT=%d", port))
+	}
+
+	if service.Command != "" {
+		return shellCommand(service.Command), dir, port, env, nil
+	}
+
+	// Without a command, the service starts like 'xypcli start' in its directory
+	pkg, err := readPackageJSON(dir)
+	if err != nil {
+		return nil, "", 0, nil, environmentError("service '%s' has no command and no readable package.json in %s", service.Name, dir)
+	}
+	manager, _, err := c.detectPackageManager(dir, pkg, "")
+	if err != nil {
+		return nil, "", 0, nil, err
+	}
+	// The plan is resolved from the absolute directory: the runner (e.g. node_modules/.bin/quickdev)
+	// is then started with the service directory as working directory
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", 0, nil, failureError(err, "cannot resolve the directory of service '%s'", service.Name)
+	}
+	plan, _ := resolveStartPlan(absDir, StartOptions{}, pkg, manager)
+	if len(plan.Command) == 0 {
+		return nil, "", 0, nil, environmentError("no entry point found for service '%s' in %s", service.Name, dir).
+			WithHint("Set \"command\" for the service")
+	}
+	return plan.Command, dir, port, env, nil
+}
+
+// prefixWriter writes complete lines of a service prefixed with its colored name
+type prefixWriter struct {
+	prefix  string
+	mu      sync.Mutex
+	partial []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	data := append(w.partial, p...)
+	for {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			break
+		}
+		resultf("%s%s\n", w.prefix, data[:end])
+		data = data[end+1:]
+	}
+	w.partial = append([]byte{}, data...)
+	return len(p), nil
+}
+
+// flush prints an incomplete last line
+func (w *prefixWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.partial) > 0 {
+		resultf("%s%s\n", w.prefix, w.partial)
+		w.partial = nil
+	}
+}
+
+// serviceColors returns the colors used for service prefixes (read at call time, as --no-color clears them)
+func serviceColors() []string {
+	return []string{ColorCyan, ColorMagenta, ColorYellow, ColorGreen, ColorBlue}
+}
+
+// runningService is a started service
+type runningService struct {
+	def    serviceDef
+	run    *supervisedRun
+	output *prefixWriter
+	port   int
+}
+
+// StartServices starts every service of the project in dependency order
+// Each service must be ready before the next one starts; when one exits or Ctrl+C is
+// pressed, the running services are stopped in reverse order
+func (c *CLITool) StartServices(waitTimeout time.Duration) error {
+	printLogo()
+	services, source, err := loadServices(".")
+	if err != nil {
+		return err
+	}
+	ordered, err := orderServices(services)
+	if err != nil {
+		return err
+	}
+	printf("%s🚀 Starting %d services from %s...%s\n\n", ColorGreen, len(ordered), source, ColorReset)
+
+	width := 0
+	for _, service := range ordered {
+		if len(service.Name) > width {
+			width = len(service.Name)
+		}
+	}
+
+	c.handleSignals()
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, shutdownSignals...)
+	defer signal.Stop(signals)
+
+	config := loadSupervisorConfig(".")
+	running := []*runningService{}
+	exited := make(chan *runningService, len(ordered))
+
+	// shutdown stops the running services in reverse start order
+	shutdown := func(sig os.Signal) {
+		for i := len(running) - 1; i >= 0; i-- {
+			service := running[i]
+			select {
+			case <-service.run.exited:
+			default:
+				printf("%s⏹  Stopping %s...%s\n", ColorYellow, service.def.Name, ColorReset)
+				service.run.stop(sig, config.GracefulTimeout, signals)
+			}
+			service.output.flush()
+		}
+	}
+
+	// serviceExited stops the others when a service exits, during startup or afterwards
+	serviceExited := func(service *runningService) error {
+		service.output.flush()
+		reason := exitReason(service.run.waitErr)
+		printf("%s✗ %s exited (%s), stopping the other services%s\n", ColorRed, service.def.Name, reason, ColorReset)
+		shutdown(syscall.SIGTERM)
+		return failureError(service.run.waitErr, "service '%s' exited (%s)", service.def.Name, reason)
+	}
+
+	for i, service := range ordered {
+		// A service without a port is not waited for; catch it if it already crashed
+		select {
+		case exitedService := <-exited:
+			return serviceExited(exitedService)
+		default:
+		}
+
+		command, dir, port, env, err := c.resolveService(service)
+		if err == nil && port != 0 {
+			err = checkPortFree(port)
+		}
+		if err != nil {
+			shutdown(syscall.SIGTERM)
+			return err
+		}
+
+		color := serviceColors()[i%len(serviceColors())]
+		output := &prefixWriter{prefix: fmt.Sprintf("%s%-*s │%s ", color, width, service.Name, ColorReset)}
+		printf("%s▶ Starting %s%s %s(%s in %s)%s\n", ColorBold, service.Name, ColorReset, ColorDim, strings.Join(command, " "), dir, ColorReset)
+		run, err := startSupervisedRun(command, env, dir, output)
+		if err != nil {
+			shutdown(syscall.SIGTERM)
+			return err
+		}
+		current := &runningService{def: service, run: run, output: output, port: port}
+		running = append(running, current)
+		go func() {
+			<-current.run.exited
+			exited <- current
+		}()
+
+		// Readiness gating: dependents only start once this service answers
+		if port == 0 {
+			continue
+		}
+		ready := make(chan error, 1)
+		go func() {
+			_, err := waitForServer(port, waitTimeout, run.exited)
+			ready <- err
+		}()
+		select {
+		case sig := <-signals:
+			printf("\n%s⚠ Received %v, stopping the services...%s\n", ColorYellow, sig, ColorReset)
+			shutdown(sig)
+			return interruptedError()
+		case exitedService := <-exited:
+			// This service, or one started earlier, crashed while waiting
+			return serviceExited(exitedService)
+		case err := <-ready:
+			if err != nil {
+				shutdown(syscall.SIGTERM)
+				return failureError(nil, "service '%s' did not become ready: %v", service.Name, err)
+			}
+			printf("%s✓ %s ready on port %d%s\n", ColorGreen, service.Name, port, ColorReset)
+		}
+	}
+
+	// Summary of the running services
+	printf("\n%s✓ %d services running%s\n", ColorGreen, len(running), ColorReset)
+	for i, service := range running {
+		prefix := "├─"
+		if i == len(running)-1 {
+			prefix = "└─"
+		}
+		address := "no port"
+		if service.port != 0 {
+			address = fmt.Sprintf("http://localhost:%d", service.port)
+		}
+		printf("%s%s%s %s%-*s%s %s\n", ColorDim, prefix, ColorReset, ColorCyan, width, service.def.Name, ColorReset, address)
+		c.events.Emit(EventServiceReady, map[string]interface{}{
+			"name": service.def.Name,
+			"port": service.port,
+		})
+	}
+	printf("%sPress Ctrl+C to stop all services%s\n\n", ColorDim, ColorReset)
+
+	select {
+	case sig := <-signals:
+		printf("\n%s⚠ Received %v, stopping the services...%s\n", ColorYellow, sig, ColorReset)
+		shutdown(sig)
+		return interruptedError()
+	case service := <-exited:
+		return serviceExited(service)
+	}
+}
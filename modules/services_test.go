@@ -0,0 +1,72 @@
+package modules
+
+import (
+	"strings"
+	"testing"
+)
+
+// testService returns a service definition named name that depends on dependsOn
+func testService(name string, dependsOn ...string) serviceDef {
+	return serviceDef{Name: name, DependsOn: dependsOn}
+}
+
+func TestValidateServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		services []serviceDef
+		want     string
+	}{
+		{"valid", []serviceDef{testService("db"), testService("api", "db")}, ""},
+		{"self dependency is left to orderServices", []serviceDef{testService("api", "api")}, ""},
+		{"missing name", []serviceDef{testService("db"), testService("")}, "service #2 in xypcli.services.json has no name"},
+		{"duplicate name", []serviceDef{testService("api"), testService("db"), testService("api")}, "service 'api' is defined twice in xypcli.services.json"},
+		{"unknown dependency", []serviceDef{testService("api", "db", "cache"), testService("db")}, "service 'api' depends on unknown service 'cache' in xypcli.services.json"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateServices(test.services, ServicesFile)
+			if test.want == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			checkEnvironmentError(t, err, test.want)
+		})
+	}
+}
+
+func TestOrderServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		services []serviceDef
+		want     string // Names in start order, or the error message
+	}{
+		{"declaration order", []serviceDef{testService("web"), testService("api"), testService("db")}, "web api db"},
+		{"dependencies first", []serviceDef{testService("web", "api"), testService("api", "db"), testService("db")}, "db api web"},
+		{"independent services keep their order", []serviceDef{testService("web", "db"), testService("worker"), testService("db")}, "db web worker"},
+		{"diamond", []serviceDef{testService("web", "auth", "api"), testService("auth", "db"), testService("api", "db"), testService("db")}, "db auth api web"},
+		{"self dependency", []serviceDef{testService("db"), testService("api", "api")}, "services have a dependency cycle: api → api"},
+		{"two-service cycle", []serviceDef{testService("a", "b"), testService("b", "a")}, "services have a dependency cycle: a → b → a"},
+		{"longer cycle", []serviceDef{testService("web", "api"), testService("api", "auth"), testService("auth", "db"), testService("db", "api")}, "services have a dependency cycle: web → api → auth → db → api"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ordered, err := orderServices(test.services)
+			if strings.Contains(test.want, "cycle") {
+				checkEnvironmentError(t, err, test.want)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := []string{}
+			for _, service := range ordered {
+				names = append(names, service.Name)
+			}
+			if got := strings.Join(names, " "); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
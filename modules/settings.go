@@ -0,0 +1,587 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProjectSettingsFile is the project-level CLI configuration file
+// It is looked up in the current directory and its parents
+const ProjectSettingsFile = ".xypclirc"
+
+// SettingsEnvPrefix prefixes the environment variables that override settings (e.g. XYPCLI_AUTHOR)
+const SettingsEnvPrefix = "XYPCLI_"
+
+// Setting sources, from lowest to highest priority (command-line flags come last)
+const (
+	SourceBuiltin = "built-in"
+	SourceUser    = "user"
+	SourceProject = "project"
+	SourceEnv     = "env"
+)
+
+// settingKeys lists every supported setting with its type, default and allowed values
+// The project name is not a setting: a default name would be wrong for every project but one
+var settingKeys = []FlagDef{
+	{Name: "author", Type: FlagString, Usage: "Author of new projects", Default: "Nehonix-Team"},
+	{Name: "alias", Type: FlagString, Usage: "Application alias of new projects", Default: "XyP"},
+	{Name: "description", Type: FlagString, Usage: "Description of new projects"},
+	{Name: "lang", Type: FlagString, Usage: "Language of new projects", Default: "ts", Values: []string{"js", "ts"}},
+	{Name: "port", Type: FlagInt, Usage: "Server port of new projects", Default: "3000"},
+	{Name: "app-version", Type: FlagString, Usage: "Application version of new projects", Default: "1.0.0"},
+	{Name: "mode", Type: FlagString, Usage: "Package manager: 'b' for bun, 'n' for npm (empty: auto)", Values: []string{"", "b", "n"}},
+	{Name: "strict", Type: FlagBool, Usage: "Stop at the first package that fails to install", Default: "false"},
+	{Name: "concurrency", Type: FlagInt, Usage: "Maximum number of parallel package installs", Default: "4"},
+	{Name: "template", Type: FlagString, Usage: "Template zip used by init: URL or local path (empty: Nehonix SDK)"},
+	{Name: "registry", Type: FlagString, Usage: "npm registry used for package installs (empty: package manager default)"},
+	{Name: "color", Type: FlagString, Usage: "Color output: auto, always or never", Default: "auto", Values: []string{"auto", "always", "never"}},
+}
+
+// settingsDocument is one configuration file
+// The whole JSON document is kept so unknown keys survive "config set", and changes are
+// applied to its text so the layout of a hand-edited file is kept
+type settingsDocument struct {
+	path   string
+	data   map[string]interface{}
+	source *jsonDocument
+}
+
+// Settings resolves CLI settings from flags, environment, project and user configuration
+// Precedence: flag > env (XYPCLI_*) > project (.xypclirc) > user (~/.config/xypcli/config.json) > built-in
+// Within a file, the active profile overrides the top-level values
+type Settings struct {
+	Profile string
+	user    *settingsDocument
+	project *settingsDocument
+}
+
+// UserSettingsPath returns the path of the user configuration file
+func UserSettingsPath() (string, error) {
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		return filepath.Join(configHome, "xypcli", "config.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "xypcli", "config.json"), nil
+}
+
+// findProjectSettings returns the closest .xypclirc in the current directory or its parents
+func findProjectSettings() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, ProjectSettingsFile)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readSettingsDocument reads a configuration file; a missing file is an empty document
+func readSettingsDocument(path string) (*settingsDocument, error) {
+	doc := &settingsDocument{path: path, data: map[string]interface{}{}, source: newJSONDocument(path)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return doc, nil
+	}
+	if err != nil {
+		return doc, environmentError("failed to read %s: %v", path, err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return doc, nil
+	}
+	if err := json.Unmarshal(data, &doc.data); err != nil {
+		return doc, environmentError("invalid JSON in %s: %v", path, err).
+			WithHint("Fix it with 'xypcli config edit'")
+	}
+	if doc.data == nil {
+		doc.data = map[string]interface{}{}
+	}
+	if source, err := parseJSONDocument(path, data); err == nil {
+		doc.source = source
+	}
+	return doc, nil
+}
+
+// write saves the document, creating its directory if needed
+func (d *settingsDocument) write() error {
+	if err := os.MkdirAll(filepath.Dir(d.path), 0755); err != nil {
+		return failureError(err, "failed to create %s", filepath.Dir(d.path))
+	}
+	if err := d.source.write(); err != nil {
+		return failureError(err, "failed to write %s", d.path)
+	}
+	return nil
+}
+
+// settingPath returns the JSON path of a setting, in a profile or at the top level
+func settingPath(profile, key string) []string {
+	if profile == "" {
+		return []string{key}
+	}
+	return []string{"profiles", profile, key}
+}
+
+// set assigns a setting in the top-level values or in a profile
+func (d *settingsDocument) set(profile, key string, value interface{}) error {
+	d.section(profile, true)[key] = value
+	if err := d.source.set(value, settingPath(profile, key)...); err != nil {
+		return environmentError("cannot set %s in %s: %v", key, d.path, err)
+	}
+	return nil
+}
+
+// unset removes a setting; returns false when it was not set
+func (d *settingsDocument) unset(profile, key string) bool {
+	values := d.section(profile, false)
+	if _, ok := values[key]; !ok {
+		return false
+	}
+	delete(values, key)
+	d.source.remove(settingPath(profile, key)...)
+	return true
+}
+
+// section returns the top-level values, or the values of a profile
+func (d *settingsDocument) section(profile string, create bool) map[string]interface{} {
+	if profile == "" {
+		return d.data
+	}
+	profiles, _ := d.data["profiles"].(map[string]interface{})
+	if profiles == nil {
+		if !create {
+			return nil
+		}
+		profiles = map[string]interface{}{}
+		d.data["profiles"] = profiles
+	}
+	values, _ := profiles[profile].(map[string]interface{})
+	if values == nil && create {
+		values = map[string]interface{}{}
+		profiles[profile] = values
+	}
+	return values
+}
+
+// profiles returns the profile names defined in the document
+func (d *settingsDocument) profiles() []string {
+	profiles, _ := d.data["profiles"].(map[string]interface{})
+	names := []string{}
+	for name := range profiles {
+		names = append(names, name)
+	}
+	return names
+}
+
+// LoadSettings reads the user and project configuration files
+// The returned Settings is always usable (built-in defaults at worst), even with an error
+func LoadSettings(profile string) (*Settings, error) {
+	if profile == "" {
+		profile = os.Getenv(SettingsEnvPrefix + "PROFILE")
+	}
+	settings := &Settings{Profile: profile}
+
+	userPath, err := UserSettingsPath()
+	if err == nil {
+		settings.user, err = readSettingsDocument(userPath)
+		if err != nil {
+			return settings, err
+		}
+	}
+	if projectPath := findProjectSettings(); projectPath != "" {
+		settings.project, err = readSettingsDocument(projectPath)
+		if err != nil {
+			return settings, err
+		}
+	}
+
+	if profile != "" && !containsString(settings.Profiles(), profile) {
+		err := usageError("unknown profile '%s'", profile)
+		if suggestion := suggest(profile, settings.Profiles()); suggestion != "" {
+			return settings, err.WithHint("Did you mean '%s'?", suggestion)
+		}
+		return settings, err.WithHint("Create it with 'xypcli config set <key> <value> --profile %s'", profile)
+	}
+
+	for _, layer := range settings.layers() {
+		if err := validateSettingsSection(layer.values, layer.source); err != nil {
+			return settings, err
+		}
+	}
+	for _, definition := range settingKeys {
+		if value, ok := os.LookupEnv(settingEnvName(definition.Name)); ok {
+			if _, err := parseSettingValue(definition, value); err != nil {
+				return settings, environmentError("%s in %s", asCLIError(err).Message, settingEnvName(definition.Name))
+			}
+		}
+	}
+	return settings, nil
+}
+
+// Profiles returns every profile defined in the user and project files
+func (s *Settings) Profiles() []string {
+	names := []string{}
+	for _, doc := range []*settingsDocument{s.user, s.project} {
+		if doc == nil {
+			continue
+		}
+		for _, name := range doc.profiles() {
+			if !containsString(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// settingsLayer is one source of values, e.g. the "work" profile of the user file
+type settingsLayer struct {
+	source string // Shown by "config list" (e.g. "user (profile work)")
+	values map[string]interface{}
+}
+
+// layers returns the file-based sources from highest to lowest priority
+func (s *Settings) layers() []settingsLayer {
+	layers := []settingsLayer{}
+	for _, entry := range []struct {
+		name string
+		doc  *settingsDocument
+	}{{SourceProject, s.project}, {SourceUser, s.user}} {
+		if entry.doc == nil {
+			continue
+		}
+		if s.Profile != "" {
+			if values := entry.doc.section(s.Profile, false); values != nil {
+				layers = append(layers, settingsLayer{fmt.Sprintf("%s (profile %s): %s", entry.name, s.Profile, entry.doc.path), values})
+			}
+		}
+		layers = append(layers, settingsLayer{entry.name + ": " + entry.doc.path, entry.doc.data})
+	}
+	return layers
+}
+
+// Lookup returns the resolved value of a setting and where it comes from
+func (s *Settings) Lookup(key string) (string, string) {
+	if value, ok := os.LookupEnv(settingEnvName(key)); ok {
+		return value, SourceEnv + ": " + settingEnvName(key)
+	}
+	for _, layer := range s.layers() {
+		if raw, ok := layer.values[key]; ok {
+			return settingString(raw), layer.source
+		}
+	}
+	definition, _ := lookupFlag(settingKeys, key, false)
+	return definition.Default, SourceBuiltin
+}
+
+// IsDefault reports whether a setting has its built-in value
+func (s *Settings) IsDefault(key string) bool {
+	_, source := s.Lookup(key)
+	return source == SourceBuiltin
+}
+
+// String returns the resolved value of a setting
+func (s *Settings) String(key string) string {
+	value, _ := s.Lookup(key)
+	return value
+}
+
+// Int returns the resolved value of a numeric setting
+func (s *Settings) Int(key string) int {
+	value, _ := strconv.Atoi(s.String(key))
+	return value
+}
+
+// Bool returns the resolved value of a boolean setting
+func (s *Settings) Bool(key string) bool {
+	value, _ := strconv.ParseBool(s.String(key))
+	return value
+}
+
+// flagOrSetting returns the flag value when given on the command line, otherwise the setting
+// With configuredOnly, built-in defaults are ignored so interactive commands still prompt for them
+func (c *CLITool) flagOrSetting(ctx *CommandContext, key string, configuredOnly bool) string {
+	if ctx.IsSet(key) {
+		return ctx.String(key)
+	}
+	if configuredOnly && c.settings.IsDefault(key) {
+		return ""
+	}
+	return c.settings.String(key)
+}
+
+// settingEnvName returns the environment variable of a setting (e.g. XYPCLI_APP_VERSION)
+func settingEnvName(key string) string {
+	return SettingsEnvPrefix + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// settingString converts a JSON value to its string form
+func settingString(raw interface{}) string {
+	switch value := raw.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	case nil:
+		return ""
+	}
+	encoded, _ := json.Marshal(raw)
+	return string(encoded)
+}
+
+// settingDefinition returns the definition of a setting, or a usage error with a suggestion
+func settingDefinition(key string) (FlagDef, error) {
+	if definition, ok := lookupFlag(settingKeys, key, false); ok {
+		return definition, nil
+	}
+	err := usageError("unknown setting '%s'", key)
+	if suggestion := suggest(key, settingNames()); suggestion != "" {
+		return FlagDef{}, err.WithHint("Did you mean '%s'?", suggestion)
+	}
+	return FlagDef{}, err.WithHint("Run 'xypcli config list' to see the available settings")
+}
+
+// settingNames returns the names of every setting
+func settingNames() []string {
+	names := []string{}
+	for _, definition := range settingKeys {
+		names = append(names, definition.Name)
+	}
+	return names
+}
+
+// parseSettingValue validates a value given on the command line and converts it to its JSON type
+func parseSettingValue(definition FlagDef, value string) (interface{}, error) {
+	if len(definition.Values) > 0 && !containsString(definition.Values, value) {
+		return nil, usageError("invalid value '%s' for %s (expected %s)", value, definition.Name, strings.Join(nonEmpty(definition.Values), ", "))
+	}
+	switch definition.Type {
+	case FlagInt:
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 0 {
+			return nil, usageError("invalid value '%s' for %s (expected a number)", value, definition.Name)
+		}
+		return number, nil
+	case FlagBool:
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, usageError("invalid value '%s' for %s (expected true or false)", value, definition.Name)
+		}
+		return flag, nil
+	}
+	return value, nil
+}
+
+// validateSettingsSection checks the known keys of a file section
+// Unknown keys are ignored so newer files keep working with older CLIs
+func validateSettingsSection(values map[string]interface{}, source string) error {
+	for key, raw := range values {
+		definition, ok := lookupFlag(settingKeys, key, false)
+		if !ok {
+			continue
+		}
+		if _, err := parseSettingValue(definition, settingString(raw)); err != nil {
+			target := ""
+			if strings.HasPrefix(source, SourceProject) {
+				target = " --project"
+			}
+			return environmentError("%s in %s", asCLIError(err).Message, source).
+				WithHint("Fix it with 'xypcli config set %s <value>%s' or 'xypcli config edit%s'", key, target, target)
+		}
+	}
+	return nil
+}
+
+// nonEmpty returns the non-empty strings of a list
+func nonEmpty(values []string) []string {
+	result := []string{}
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// settingsTarget returns the file changed by "config set/unset/edit"
+func settingsTarget(project bool) (*settingsDocument, error) {
+	if project {
+		path := findProjectSettings()
+		if path == "" {
+			path = ProjectSettingsFile
+		}
+		return readSettingsDocument(path)
+	}
+	path, err := UserSettingsPath()
+	if err != nil {
+		return nil, environmentError("could not locate your home directory: %v", err)
+	}
+	return readSettingsDocument(path)
+}
+
+// ConfigGet prints the resolved value of a setting
+func (c *CLITool) ConfigGet(key string) error {
+	if _, err := settingDefinition(key); err != nil {
+		return err
+	}
+	if c.settingsErr != nil {
+		return c.settingsErr
+	}
+	resultf("%s\n", c.settings.String(key))
+	return nil
+}
+
+// ConfigList prints every setting with its resolved value and its source
+func (c *CLITool) ConfigList() error {
+	if c.settingsErr != nil {
+		return c.settingsErr
+	}
+	width := 0
+	for _, definition := range settingKeys {
+		if len(definition.Name) > width {
+			width = len(definition.Name)
+		}
+	}
+	if c.settings.Profile != "" {
+		printf("%sProfile: %s%s\n\n", ColorBold, c.settings.Profile, ColorReset)
+	}
+	for _, definition := range settingKeys {
+		value, source := c.settings.Lookup(definition.Name)
+		if value == "" {
+			value = "(empty)"
+		}
+		resultf("%s%s%s%s = %s  %s(%s)%s\n", ColorCyan, definition.Name, ColorReset,
+			strings.Repeat(" ", width-len(definition.Name)), value, ColorDim, source, ColorReset)
+	}
+	if profiles := c.settings.Profiles(); len(profiles) > 0 {
+		printf("\n%sProfiles: %s%s\n", ColorDim, strings.Join(profiles, ", "), ColorReset)
+	}
+	return nil
+}
+
+// ConfigSet stores a setting in the user file (or the project file), optionally in a profile
+func (c *CLITool) ConfigSet(key, value, profile string, project bool) error {
+	definition, err := settingDefinition(key)
+	if err != nil {
+		return err
+	}
+	parsed, err := parseSettingValue(definition, value)
+	if err != nil {
+		return err
+	}
+	doc, err := settingsTarget(project)
+	if err != nil {
+		return err
+	}
+	if err := doc.set(profile, key, parsed); err != nil {
+		return err
+	}
+	if err := doc.write(); err != nil {
+		return err
+	}
+	printf("%s✓ %s = %s%s %s(%s)%s\n", ColorGreen, key, value, ColorReset, ColorDim, describeTarget(doc, profile), ColorReset)
+	return nil
+}
+
+// ConfigUnset removes a setting from the user file (or the project file)
+func (c *CLITool) ConfigUnset(key, profile string, project bool) error {
+	if _, err := settingDefinition(key); err != nil {
+		return err
+	}
+	doc, err := settingsTarget(project)
+	if err != nil {
+		return err
+	}
+	if !doc.unset(profile, key) {
+		printf("%s%s is not set in %s%s\n", ColorDim, key, describeTarget(doc, profile), ColorReset)
+		return nil
+	}
+	if err := doc.write(); err != nil {
+		return err
+	}
+	printf("%s✓ %s removed%s %s(%s)%s\n", ColorGreen, key, ColorReset, ColorDim, describeTarget(doc, profile), ColorReset)
+	return nil
+}
+
+// ConfigEdit opens the user file (or the project file) in $VISUAL / $EDITOR and validates the result
+func (c *CLITool) ConfigEdit(project bool) error {
+	doc, err := settingsTarget(project)
+	if err != nil && doc == nil {
+		return err
+	}
+	if _, statErr := os.Stat(doc.path); os.IsNotExist(statErr) {
+		if err := doc.write(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.runEditor(doc.path); err != nil {
+		return err
+	}
+
+	edited, err := readSettingsDocument(doc.path)
+	if err != nil {
+		return err
+	}
+	if err := validateSettingsSection(edited.data, doc.path); err != nil {
+		return err
+	}
+	for _, name := range edited.profiles() {
+		if err := validateSettingsSection(edited.section(name, false), doc.path+" (profile "+name+")"); err != nil {
+			return err
+		}
+	}
+	printf("%s✓ %s saved%s\n", ColorGreen, doc.path, ColorReset)
+	return nil
+}
+
+// runEditor opens path in $VISUAL / $EDITOR (vi or notepad by default) and waits for it to close
+func (c *CLITool) runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	c.handleSignals()
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return environmentError("failed to run editor '%s': %v", editor, err).
+			WithHint("Set $EDITOR to your preferred editor")
+	}
+	return nil
+}
+
+// describeTarget describes the file and profile changed by a config command
+func describeTarget(doc *settingsDocument, profile string) string {
+	if profile != "" {
+		return doc.path + ", profile " + profile
+	}
+	return doc.path
+}
@@ -0,0 +1,285 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// maxRestartBackoff caps the exponential delay between two restarts
+const maxRestartBackoff = 30 * time.Second
+
+// shutdownSignals are forwarded to the server before the supervisor stops
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
+
+// supervisorConfig controls restarts and shutdown of the development server
+// The values mirror the keys of quickdev.config.json
+type supervisorConfig struct {
+	MaxRestarts        int           // Crashes tolerated within ResetRestartsAfter
+	ResetRestartsAfter time.Duration // A run longer than this resets the restart count
+	RestartDelay       time.Duration // Delay before the first restart, doubled after each crash
+	GracefulTimeout    time.Duration // Time given to the server to exit before SIGKILL
+}
+
+// loadSupervisorConfig reads the restart settings of quickdev.config.json in dir
+// Missing keys keep the quickdev defaults
+func loadSupervisorConfig(dir string) supervisorConfig {
+	config := supervisorConfig{
+		MaxRestarts:        5,
+		ResetRestartsAfter: 60 * time.Second,
+		RestartDelay:       100 * time.Millisecond,
+		GracefulTimeout:    5 * time.Second,
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, QuickdevConfigFile))
+	if err != nil {
+		return config
+	}
+	var raw struct {
+		MaxRestarts             *int     `json:"maxRestarts"`             // Count
+		ResetRestartsAfter      *int     `json:"resetRestartsAfter"`      // Milliseconds
+		RestartDelay            *int     `json:"restartDelay"`            // Milliseconds
+		GracefulShutdown        *bool    `json:"gracefulShutdown"`        // false skips the graceful timeout
+		GracefulShutdownTimeout *float64 `json:"gracefulShutdownTimeout"` // Seconds
+	}
+	if json.Unmarshal(data, &raw) != nil {
+		printf("  %s⚠ Ignoring invalid %s%s\n", ColorYellow, QuickdevConfigFile, ColorReset)
+		return config
+	}
+	if raw.MaxRestarts != nil && *raw.MaxRestarts >= 0 {
+		config.MaxRestarts = *raw.MaxRestarts
+	}
+	if raw.ResetRestartsAfter != nil && *raw.ResetRestartsAfter > 0 {
+		config.ResetRestartsAfter = time.Duration(*raw.ResetRestartsAfter) * time.Millisecond
+	}
+	if raw.RestartDelay != nil && *raw.RestartDelay >= 0 {
+		config.RestartDelay = time.Duration(*raw.RestartDelay) * time.Millisecond
+	}
+	if raw.GracefulShutdownTimeout != nil && *raw.GracefulShutdownTimeout >= 0 {
+		config.GracefulTimeout = time.Duration(*raw.GracefulShutdownTimeout * float64(time.Second))
+	}
+	if raw.GracefulShutdown != nil && !*raw.GracefulShutdown {
+		config.GracefulTimeout = 0
+	}
+	return config
+}
+
+// restartBackoff returns the delay before the given restart (1-based)
+func (config supervisorConfig) restartBackoff(restart int) time.Duration {
+	delay := config.RestartDelay
+	for i := 1; i < restart && delay < maxRestartBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRestartBackoff {
+		delay = maxRestartBackoff
+	}
+	return delay
+}
+
+// countCrash returns the restart count after a crash that ended a run of uptime,
+// and whether the server may be restarted (a long run starts a new crash loop)
+func (config supervisorConfig) countCrash(restarts int, uptime time.Duration) (int, bool) {
+	if uptime >= config.ResetRestartsAfter {
+		restarts = 0
+	}
+	restarts++
+	return restarts, restarts <= config.MaxRestarts
+}
+
+// serverExit records one exit of the supervised server
+type serverExit struct {
+	Reason string
+	Uptime time.Duration
+}
+
+// exitReason describes why a process ended (e.g. "exit code 1", "signal: killed")
+func exitReason(err error) string {
+	if err == nil {
+		return "exit code 0"
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if code := exitErr.ExitCode(); code >= 0 {
+			return fmt.Sprintf("exit code %d", code)
+		}
+		return exitErr.String()
+	}
+	return err.Error()
+}
+
+// supervisedRun is one run of the server process
+type supervisedRun struct {
+	cmd     *exec.Cmd
+	exited  chan struct{} // Closed when the process ended
+	waitErr error         // Set before exited is closed
+	started time.Time
+}
+
+// startSupervisedRun starts the server in its own process group, in dir (or the current directory)
+func startSupervisedRun(command, env []string, dir string, output io.Writer) (*supervisedRun, error) {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.Env = env
+	setProcessGroup(cmd)
+
+	run := &supervisedRun{cmd: cmd, exited: make(chan struct{}), started: time.Now()}
+	if err := cmd.Start(); err != nil {
+		if _, ok := err.(*exec.Error); ok {
+			return nil, environmentError("could not run '%s': %v", command[0], err).
+				WithHint("Install it, or choose another entry point with --entry")
+		}
+		return nil, failureError(err, "failed to start the development server")
+	}
+	go func() {
+		run.waitErr = cmd.Wait()
+		close(run.exited)
+	}()
+	return run, nil
+}
+
+// stop forwards sig to the process group, then kills it when it is still running
+// after the graceful timeout (or at once on a second signal)
+func (run *supervisedRun) stop(sig os.Signal, timeout time.Duration, signals <-chan os.Signal) {
+	signalProcessGroup(run.cmd, sig)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-run.exited:
+		return
+	case <-timer.C:
+		printf("%s⚠ Server still running after %s, killing it%s\n", ColorYellow, timeout, ColorReset)
+	case <-signals:
+		printf("%s⚠ Killing the server%s\n", ColorYellow, ColorReset)
+	}
+	killProcessGroup(run.cmd)
+	<-run.exited
+}
+
+// superviseServer runs the development server and restarts it when it crashes
+// A run shorter than ResetRestartsAfter counts towards MaxRestarts; the delay between
+// restarts doubles after each crash. SIGINT/SIGTERM/SIGHUP are forwarded to the server's
+// process group, which gets GracefulTimeout to exit before SIGKILL
+func (c *CLITool) superviseServer(command, env []string, config supervisorConfig, port int, waitTimeout time.Duration) error {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, shutdownSignals...)
+	defer signal.Stop(signals)
+
+	exits := []serverExit{}
+	restarts := 0
+	everReady := false
+
+	for {
+		run, err := startSupervisedRun(command, env, "", os.Stdout)
+		if err != nil {
+			return err
+		}
+
+		// Readiness is polled in the background while the supervisor watches signals
+		type readiness struct {
+			result int
+			err    error
+		}
+		ready := make(chan readiness, 1)
+		if port != 0 {
+			go func() {
+				result, err := waitForServer(port, waitTimeout, run.exited)
+				ready <- readiness{result, err}
+			}()
+		}
+
+	wait:
+		for {
+			select {
+			case sig := <-signals:
+				printf("\n%s⚠ Received %v, stopping the server...%s\n", ColorYellow, sig, ColorReset)
+				run.stop(sig, config.GracefulTimeout, signals)
+				printExitSummary(exits)
+				return interruptedError()
+
+			case r := <-ready:
+				if r.err != nil {
+					select {
+					case <-run.exited:
+						break wait // The crash is handled below
+					default:
+					}
+					if everReady {
+						printf("%s⚠ Server not ready %s after restarting%s\n", ColorYellow, waitTimeout, ColorReset)
+						continue
+					}
+					run.stop(syscall.SIGTERM, config.GracefulTimeout, signals)
+					return r.err
+				}
+				if everReady {
+					printf("%s✓ Server back up after %.1fs%s\n", ColorGreen, time.Since(run.started).Seconds(), ColorReset)
+				} else {
+					c.announceReady(port, r.result == probeHealthy, run.started)
+				}
+				everReady = true
+
+			case <-run.exited:
+				break wait
+			}
+		}
+
+		// The server exited on its own
+		uptime := time.Since(run.started)
+		if run.waitErr == nil {
+			printf("%s✓ Server exited cleanly after %s%s\n", ColorGreen, uptime.Round(time.Millisecond), ColorReset)
+			printExitSummary(exits)
+			return nil
+		}
+
+		reason := exitReason(run.waitErr)
+		exits = append(exits, serverExit{Reason: reason, Uptime: uptime})
+		var canRestart bool
+		restarts, canRestart = config.countCrash(restarts, uptime)
+
+		c.events.Emit(EventServerExited, map[string]interface{}{
+			"reason":   reason,
+			"uptimeMs": uptime.Milliseconds(),
+			"restart":  restarts,
+		})
+
+		if !canRestart {
+			printf("%s✗ Server crashed (%s) after %s%s\n", ColorRed, reason, uptime.Round(time.Millisecond), ColorReset)
+			printExitSummary(exits)
+			return failureError(run.waitErr, "development server crashed %d time(s) within %s, giving up", restarts, config.ResetRestartsAfter).
+				WithHint("Fix the error above, or raise maxRestarts in %s", QuickdevConfigFile)
+		}
+
+		delay := config.restartBackoff(restarts)
+		printf("%s✗ Server crashed (%s) after %s, restart %d/%d in %s%s\n",
+			ColorRed, reason, uptime.Round(time.Millisecond), restarts, config.MaxRestarts, delay, ColorReset)
+
+		select {
+		case sig := <-signals:
+			printf("\n%s⚠ Received %v, not restarting%s\n", ColorYellow, sig, ColorReset)
+			printExitSummary(exits)
+			return interruptedError()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// printExitSummary lists why the server exited before each restart
+func printExitSummary(exits []serverExit) {
+	if len(exits) == 0 {
+		return
+	}
+	printf("\n%sServer exits:%s\n", ColorBold, ColorReset)
+	for i, exit := range exits {
+		prefix := "├─"
+		if i == len(exits)-1 {
+			prefix = "└─"
+		}
+		printf("%s%s%s #%d %s after %s\n", ColorDim, prefix, ColorReset, i+1, exit.Reason, exit.Uptime.Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,106 @@
+package modules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRestartBackoff(t *testing.T) {
+	config := supervisorConfig{RestartDelay: 100 * time.Millisecond}
+	tests := []struct {
+		restart int
+		want    time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{6, 3200 * time.Millisecond},
+		{9, 25600 * time.Millisecond},
+		{10, maxRestartBackoff},
+		{1000, maxRestartBackoff},
+	}
+	for _, test := range tests {
+		if got := config.restartBackoff(test.restart); got != test.want {
+			t.Errorf("restart %d: got %s, want %s", test.restart, got, test.want)
+		}
+	}
+
+	if got := (supervisorConfig{RestartDelay: time.Minute}).restartBackoff(1); got != maxRestartBackoff {
+		t.Errorf("a first delay above the cap gave %s, want %s", got, maxRestartBackoff)
+	}
+	if got := (supervisorConfig{}).restartBackoff(50); got != 0 {
+		t.Errorf("restartDelay 0 gave %s, want 0", got)
+	}
+}
+
+func TestCountCrash(t *testing.T) {
+	config := supervisorConfig{MaxRestarts: 3, ResetRestartsAfter: time.Minute}
+
+	// Quick crashes use up the restarts, then the supervisor gives up
+	restarts := 0
+	for i := 1; i <= 4; i++ {
+		var canRestart bool
+		restarts, canRestart = config.countCrash(restarts, time.Second)
+		if restarts != i || canRestart != (i <= 3) {
+			t.Errorf("crash %d: got count %d, restart %v", i, restarts, canRestart)
+		}
+	}
+
+	// A run longer than resetRestartsAfter starts a new crash loop
+	if restarts, canRestart := config.countCrash(3, time.Minute); restarts != 1 || !canRestart {
+		t.Errorf("crash after a long run: got count %d, restart %v", restarts, canRestart)
+	}
+
+	// maxRestarts 0 never restarts
+	if _, canRestart := (supervisorConfig{ResetRestartsAfter: time.Minute}).countCrash(0, time.Hour); canRestart {
+		t.Error("maxRestarts 0 allowed a restart")
+	}
+}
+
+func TestLoadSupervisorConfig(t *testing.T) {
+	defaults := supervisorConfig{
+		MaxRestarts:        5,
+		ResetRestartsAfter: 60 * time.Second,
+		RestartDelay:       100 * time.Millisecond,
+		GracefulTimeout:    5 * time.Second,
+	}
+	tests := []struct {
+		name   string
+		config string // Content of quickdev.config.json; "" for no file
+		want   supervisorConfig
+	}{
+		{"no file", "", defaults},
+		{"empty object", `{}`, defaults},
+		{"invalid JSON keeps the defaults", `{"maxRestarts": }`, defaults},
+		{
+			name:   "all keys",
+			config: `{"maxRestarts": 2, "resetRestartsAfter": 10000, "restartDelay": 250, "gracefulShutdownTimeout": 1.5}`,
+			want:   supervisorConfig{MaxRestarts: 2, ResetRestartsAfter: 10 * time.Second, RestartDelay: 250 * time.Millisecond, GracefulTimeout: 1500 * time.Millisecond},
+		},
+		{
+			name:   "zero restarts and delay",
+			config: `{"maxRestarts": 0, "restartDelay": 0}`,
+			want:   supervisorConfig{MaxRestarts: 0, ResetRestartsAfter: 60 * time.Second, RestartDelay: 0, GracefulTimeout: 5 * time.Second},
+		},
+		{"negative values are ignored", `{"maxRestarts": -1, "resetRestartsAfter": 0, "restartDelay": -5, "gracefulShutdownTimeout": -1}`, defaults},
+		{
+			name:   "gracefulShutdown false",
+			config: `{"gracefulShutdown": false, "gracefulShutdownTimeout": 10}`,
+			want:   supervisorConfig{MaxRestarts: 5, ResetRestartsAfter: 60 * time.Second, RestartDelay: 100 * time.Millisecond, GracefulTimeout: 0},
+		},
+	}
+	disableColors()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if test.config != "" {
+				writeTestFile(t, dir, QuickdevConfigFile, test.config)
+			}
+			var got supervisorConfig
+			captureStdout(t, func() { got = loadSupervisorConfig(dir) })
+			if got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package modules
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sysKey is a __sys__ value of xypriss.config.json that 'xypcli sys' can change
+type sysKey struct {
+	Name     string // Name on the command line
+	Field    string // Key in __sys__
+	Package  string // Field of package.json holding the same value, if any
+	Usage    string
+	Validate func(string) (string, error)
+}
+
+// sysKeys lists the values handled by 'xypcli sys', in display order
+var sysKeys = []sysKey{
+	{Name: "name", Field: "__name__", Package: "name", Usage: "Project name", Validate: validateProjectName},
+	{Name: "version", Field: "__version__", Package: "version", Usage: "Project version", Validate: validateVersion},
+	{Name: "description", Field: "__description__", Package: "description", Usage: "Project description"},
+	{Name: "author", Field: "__author__", Usage: "Project author"},
+	{Name: "alias", Field: "__alias__", Usage: "Short name of the application"},
+	{Name: "port", Field: "__port__", Usage: "Server port, also written to __PORT__ and the PORT of .env", Validate: validatePort},
+	{Name: "env", Field: "__env__", Usage: "Environment selected by default", Validate: validateEnvironment},
+}
+
+// sysKeyNames completes the keys of 'xypcli sys get/set'
+func sysKeyNames() []string {
+	names := make([]string, 0, len(sysKeys))
+	for _, key := range sysKeys {
+		names = append(names, key.Name)
+	}
+	return names
+}
+
+// appURLNames completes the entries of __sys__.__app_urls__
+func appURLNames() []string {
+	names := []string{}
+	for name := range readSysConfig(".").AppURLs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sysKeyDefinition finds a key by its name or its __sys__ field (e.g. port or __port__)
+func sysKeyDefinition(name string) (sysKey, error) {
+	for _, key := range sysKeys {
+		if name == key.Name || name == key.Field {
+			return key, nil
+		}
+	}
+	return sysKey{}, usageError("unknown key '%s'", name).
+		WithHint("Valid keys: %s", strings.Join(sysKeyNames(), ", "))
+}
+
+// validateEnvironment accepts one of the known environments
+func validateEnvironment(env string) (string, error) {
+	env = strings.TrimSpace(env)
+	if !containsString(environments, env) {
+		return "", fmt.Errorf("unknown environment '%s' (expected %s)", env, strings.Join(environments, ", "))
+	}
+	return env, nil
+}
+
+// packageName turns a project name into a package name, keeping the npm scope of current
+func packageName(name, current string) string {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "-"))
+	if strings.HasPrefix(current, "@") && !strings.HasPrefix(name, "@") {
+		if slash := strings.Index(current, "/"); slash > 0 {
+			return current[:slash+1] + name
+		}
+	}
+	return name
+}
+
+// readSysDocument opens the xypriss.config.json of the current project
+func readSysDocument() (*jsonDocument, error) {
+	if !fileExists(XyPrissConfigFile) {
+		return nil, environmentError("no %s found in current directory", XyPrissConfigFile).
+			WithHint("Make sure you're in a XyPriss project directory, or run 'xypcli init' to create one")
+	}
+	doc, err := readJSONDocument(XyPrissConfigFile)
+	if err != nil {
+		return nil, environmentError("%v", err).
+			WithHint("Run 'xypcli config validate' to locate the problem")
+	}
+	return doc, nil
+}
+
+// SysGet prints a __sys__ value, or every value when key is empty
+func (c *CLITool) SysGet(name string) error {
+	doc, err := readSysDocument()
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		key, err := sysKeyDefinition(name)
+		if err != nil {
+			return err
+		}
+		var value interface{}
+		if !doc.get(&value, "__sys__", key.Field) {
+			return environmentError("__sys__.%s is not set in %s", key.Field, XyPrissConfigFile).
+				WithHint("Set it with 'xypcli sys set %s <value>'", key.Name)
+		}
+		resultf("%v\n", value)
+		return nil
+	}
+
+	for _, key := range sysKeys {
+		var value interface{}
+		text := ColorDim + "(not set)" + ColorReset
+		if doc.get(&value, "__sys__", key.Field) {
+			text = fmt.Sprint(value)
+		}
+		resultf("%s%-11s%s %s\n", ColorCyan, key.Name, ColorReset, text)
+	}
+	urls := map[string]string{}
+	doc.get(&urls, "__sys__", "__app_urls__")
+	if len(urls) > 0 {
+		resultf("\n%sApp URLs:%s\n", ColorBold, ColorReset)
+		c.printAppURLs(urls)
+	}
+	return nil
+}
+
+// SysSet changes a __sys__ value and every copy of it in the project:
+// package.json name/version/description, __PORT__ and the PORT of .env
+func (c *CLITool) SysSet(name, value string) error {
+	key, err := sysKeyDefinition(name)
+	if err != nil {
+		return err
+	}
+	if key.Validate != nil {
+		if value, err = key.Validate(value); err != nil {
+			return usageError("%v", err)
+		}
+	}
+	doc, err := readSysDocument()
+	if err != nil {
+		return err
+	}
+
+	updated := []string{}
+	var stored interface{} = value
+	if key.Name == "port" {
+		stored, _ = strconv.Atoi(value)
+	}
+	if err := doc.set(stored, "__sys__", key.Field); err != nil {
+		return environmentError("cannot set __sys__.%s in %s: %v", key.Field, XyPrissConfigFile, err)
+	}
+	updated = append(updated, fmt.Sprintf("%s __sys__.%s", XyPrissConfigFile, key.Field))
+	if key.Name == "port" {
+		doc.set(stored, "__sys__", "__PORT__")
+		updated = append(updated, fmt.Sprintf("%s __sys__.__PORT__", XyPrissConfigFile))
+	}
+	if err := doc.write(); err != nil {
+		return failureError(err, "failed to write %s", XyPrissConfigFile)
+	}
+
+	if key.Package != "" && fileExists("package.json") {
+		pkg, err := readJSONDocument("package.json")
+		if err != nil {
+			return environmentError("%v", err)
+		}
+		packageValue := value
+		if key.Name == "name" {
+			var current string
+			pkg.get(&current, "name")
+			packageValue = packageName(value, current)
+		}
+		if err := pkg.set(packageValue, key.Package); err != nil {
+			return environmentError("cannot set %s in package.json: %v", key.Package, err)
+		}
+		if err := pkg.write(); err != nil {
+			return failureError(err, "failed to write package.json")
+		}
+		updated = append(updated, "package.json "+key.Package)
+	}
+
+	if key.Name == "port" && fileExists(".env") {
+		dotenv, err := readDotenvFile(".env")
+		if err != nil {
+			return err
+		}
+		dotenv.set("PORT", value)
+		if err := dotenv.write(); err != nil {
+			return err
+		}
+		updated = append(updated, ".env PORT")
+	}
+
+	printf("%s✓ %s set to %s%s\n", ColorGreen, key.Name, value, ColorReset)
+	for _, location := range updated {
+		printf("  %s→ %s%s\n", ColorDim, location, ColorReset)
+	}
+	return nil
+}
+
+// validateAppURL accepts an absolute http(s) URL or a path starting with /
+func validateAppURL(value string) error {
+	if strings.HasPrefix(value, "/") {
+		return nil
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return usageError("'%s' is not a valid URL", value).
+			WithHint("Use an http(s) URL (e.g. https://api.example.com) or a path starting with /")
+	}
+	return nil
+}
+
+// SysURLAdd adds or changes an entry of __sys__.__app_urls__
+func (c *CLITool) SysURLAdd(name, value string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return usageError("the URL name cannot be empty")
+	}
+	if err := validateAppURL(value); err != nil {
+		return err
+	}
+	doc, err := readSysDocument()
+	if err != nil {
+		return err
+	}
+	existed := doc.has("__sys__", "__app_urls__", name)
+	if err := doc.set(value, "__sys__", "__app_urls__", name); err != nil {
+		return environmentError("cannot set __sys__.__app_urls__ in %s: %v", XyPrissConfigFile, err)
+	}
+	if err := doc.write(); err != nil {
+		return failureError(err, "failed to write %s", XyPrissConfigFile)
+	}
+	verb := "added"
+	if existed {
+		verb = "changed"
+	}
+	printf("%s✓ URL %s %s%s %s(%s)%s\n", ColorGreen, name, verb, ColorReset, ColorDim, value, ColorReset)
+	return nil
+}
+
+// SysURLRemove removes an entry of __sys__.__app_urls__, and the section once it is empty
+func (c *CLITool) SysURLRemove(name string) error {
+	doc, err := readSysDocument()
+	if err != nil {
+		return err
+	}
+	if !doc.remove("__sys__", "__app_urls__", name) {
+		return environmentError("no URL named '%s' in %s", name, XyPrissConfigFile).
+			WithHint("List them with 'xypcli sys url list'")
+	}
+	urls := map[string]string{}
+	if doc.get(&urls, "__sys__", "__app_urls__") && len(urls) == 0 {
+		doc.remove("__sys__", "__app_urls__")
+	}
+	if err := doc.write(); err != nil {
+		return failureError(err, "failed to write %s", XyPrissConfigFile)
+	}
+	printf("%s✓ URL %s removed%s\n", ColorGreen, name, ColorReset)
+	return nil
+}
+
+// SysURLList prints the entries of __sys__.__app_urls__
+func (c *CLITool) SysURLList() error {
+	doc, err := readSysDocument()
+	if err != nil {
+		return err
+	}
+	urls := map[string]string{}
+	doc.get(&urls, "__sys__", "__app_urls__")
+	if len(urls) == 0 {
+		printf("%sNo app URLs%s %s(add one with 'xypcli sys url add <name> <url>')%s\n", ColorDim, ColorReset, ColorDim, ColorReset)
+		return nil
+	}
+	c.printAppURLs(urls)
+	return nil
+}
+
+// printAppURLs prints app URLs sorted by name
+func (c *CLITool) printAppURLs(urls map[string]string) {
+	names := make([]string, 0, len(urls))
+	width := 0
+	for name := range urls {
+		names = append(names, name)
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		resultf("%s%s%s%s  %s\n", ColorCyan, name, ColorReset, strings.Repeat(" ", width-len(name)), urls[name])
+	}
+}
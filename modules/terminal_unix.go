@@ -0,0 +1,18 @@
+//go:build !windows
+
+package modules
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalHeight returns the number of rows of the terminal attached to f (0 if unknown)
+func terminalHeight(f *os.File) int {
+	size, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+	return int(size.Row)
+}
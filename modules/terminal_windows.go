@@ -0,0 +1,18 @@
+//go:build windows
+
+package modules
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// terminalHeight returns the number of visible rows of the console attached to f (0 if unknown)
+func terminalHeight(f *os.File) int {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(f.Fd()), &info); err != nil {
+		return 0
+	}
+	return int(info.Window.Bottom-info.Window.Top) + 1
+}
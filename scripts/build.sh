@@ -39,6 +39,22 @@ fi
 
 print_status "Go version: $(go version)"
 
+# Run the tests, including the golden files of the help and reference docs
+print_status "Running tests..."
+if ! go test ./...; then
+    print_error "Tests failed. After an intended help change, run: go test ./modules -update"
+    exit 1
+fi
+
+# Make sure the generated reference docs match the command definitions
+print_status "Checking reference docs..."
+for format in md man; do
+    if ! go run . docs --check --format "$format" --no-color; then
+        print_error "docs/$format is out of date. Run: go run . docs --format $format"
+        exit 1
+    fi
+done
+
 # Create bin directory
 print_status "Creating bin directory..."
 mkdir -p bin